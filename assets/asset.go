@@ -28,6 +28,19 @@ func (a Asset) IsName() bool {
 	return a.Type() == NameType
 }
 
+func (a Asset) IsClaim() bool {
+	return a.Type() == ClaimType
+}
+
+func (a Asset) IsValidator() bool {
+	return a.Type() == ValidatorType
+}
+
+// IsTransferable returns false for assets which are changed by consensus only (validator seats and markers of claims)
+func (a Asset) IsTransferable() bool {
+	return !a.Empty() && !a.IsValidator() && !a.IsClaim()
+}
+
 func (a Asset) ID() uint8 {
 	return a[1]
 }
@@ -16,3 +16,12 @@ func TestAsset_Decode(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, a, b)
 }
+
+func TestIsValidName(t *testing.T) {
+	assert.True(t, IsValidName("admin"))
+	assert.True(t, IsValidName("like-coin1"))
+	assert.False(t, IsValidName("ad"))
+	assert.False(t, IsValidName("Admin"))
+	assert.False(t, IsValidName("1admin"))
+	assert.False(t, IsValidName("admin!"))
+}
@@ -1,17 +1,40 @@
 package assets
 
+import (
+	"regexp"
+
+	"github.com/denisskin/bin"
+)
+
 // Asset types
 const (
-	CoinType = 0
-	NameType = 1
+	CoinType      = 0
+	NameType      = 1
+	ClaimType     = 2 // marker of claimed cross-chain transaction
+	ValidatorType = 3 // membership in validator set of proof-of-authority consensus
 )
 
 var (
 	Likecoin = Asset{CoinType, 1} // is synonym of "YotubeCoin"
 
 	Default = Likecoin
+
+	Validators = Asset{ValidatorType} // balance of address is 1 if address is validator of blocks
 )
 
+var reName = regexp.MustCompile(`^[a-z][a-z0-9\-]{2,20}$`)
+
+// IsValidName returns true if name (username) can be registered as name-asset
+func IsValidName(name string) bool {
+	return reName.MatchString(name)
+}
+
 func NewName(name string) Asset {
 	return append(Asset{NameType}, []byte(name)...)
 }
+
+// NewClaim returns marker-asset of cross-chain transaction claimed on destination chain
+func NewClaim(srcChainID uint64, srcTxHash []byte) Asset {
+	a := append(Asset{ClaimType}, bin.Uint64ToBytes(srcChainID)...)
+	return append(a, srcTxHash...)
+}
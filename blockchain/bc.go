@@ -15,6 +15,7 @@ var (
 	ErrInvalidBlockSig      = errors.New("block.Verify-error: invalid signature")
 	ErrInvalidBlockNum      = errors.New("block.Verify-error: invalid block num")
 	ErrInvalidBlockTs       = errors.New("block.Verify-error: invalid block timestamp")
+	ErrBlockFromFuture      = errors.New("block.Verify-error: block timestamp is in the future")
 	ErrInvalidNetwork       = errors.New("block.Verify-error: invalid network ID")
 	ErrInvalidChainID       = errors.New("block.Verify-error: invalid chain ID")
 	ErrInvalidPrevHash      = errors.New("block.Verify-error: invalid previous block hash")
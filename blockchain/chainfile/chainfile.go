@@ -0,0 +1,232 @@
+// Package chainfile implements a portable file format of blockchain blocks.
+//
+// File layout:
+//
+//	header  (fixed size)  magic, version, network, chainID, blocks range, count of blocks, checksum
+//	records               uvarint(len(data)) + data,  where data := Block.Encode()
+//
+// Checksum is sha3-256 of all records.
+package chainfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/crypto/sha3"
+)
+
+const (
+	Version = 1
+
+	headerSize    = 8 + 4 + 4 + 8 + 8 + 8 + 8 + 32
+	maxRecordSize = 64 << 20
+)
+
+var magic = [8]byte{'L', 'I', 'K', 'E', 'C', 'H', 'N', 0}
+
+var (
+	ErrInvalidFormat   = errors.New("chainfile: invalid file format")
+	ErrInvalidVersion  = errors.New("chainfile: unsupported file version")
+	ErrInvalidChecksum = errors.New("chainfile: invalid checksum")
+	ErrInvalidBlockNum = errors.New("chainfile: invalid block num")
+	ErrInvalidChainID  = errors.New("chainfile: block chain ID does not match file header")
+)
+
+type Header struct {
+	Version  uint32 `json:"version"`  //
+	Network  int    `json:"network"`  //
+	ChainID  uint64 `json:"chain"`    //
+	From     uint64 `json:"from"`     // first block num
+	To       uint64 `json:"to"`       // last block num
+	Count    uint64 `json:"count"`    // count of blocks
+	Checksum []byte `json:"checksum"` // sha3-256 of records
+}
+
+func (h *Header) encode() []byte {
+	buf := make([]byte, headerSize)
+	copy(buf, magic[:])
+	binary.BigEndian.PutUint32(buf[8:], h.Version)
+	binary.BigEndian.PutUint32(buf[12:], uint32(h.Network))
+	binary.BigEndian.PutUint64(buf[16:], h.ChainID)
+	binary.BigEndian.PutUint64(buf[24:], h.From)
+	binary.BigEndian.PutUint64(buf[32:], h.To)
+	binary.BigEndian.PutUint64(buf[40:], h.Count)
+	copy(buf[48:], h.Checksum)
+	return buf
+}
+
+func (h *Header) decode(buf []byte) error {
+	if len(buf) != headerSize || !bytes.Equal(buf[:8], magic[:]) {
+		return ErrInvalidFormat
+	}
+	h.Version = binary.BigEndian.Uint32(buf[8:])
+	h.Network = int(binary.BigEndian.Uint32(buf[12:]))
+	h.ChainID = binary.BigEndian.Uint64(buf[16:])
+	h.From = binary.BigEndian.Uint64(buf[24:])
+	h.To = binary.BigEndian.Uint64(buf[32:])
+	h.Count = binary.BigEndian.Uint64(buf[40:])
+	h.Checksum = append([]byte{}, buf[48:]...)
+	if h.Version != Version {
+		return ErrInvalidVersion
+	}
+	return nil
+}
+
+//------------------ writer ------------------------
+
+type Writer struct {
+	w    io.WriteSeeker
+	buf  *bufio.Writer
+	hash hash.Hash
+	hdr  Header
+}
+
+// NewWriter writes an empty file header; the header is filled by Close
+func NewWriter(w io.WriteSeeker, network int, chainID uint64) (*Writer, error) {
+	fw := &Writer{
+		w:    w,
+		buf:  bufio.NewWriter(w),
+		hash: sha3.New256(),
+		hdr: Header{
+			Version: Version,
+			Network: network,
+			ChainID: chainID,
+		},
+	}
+	if _, err := fw.buf.Write(fw.hdr.encode()); err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+func (w *Writer) WriteBlock(block *blockchain.Block) error {
+	if block.ChainID != w.hdr.ChainID || block.Network != w.hdr.Network {
+		return ErrInvalidChainID
+	}
+	if w.hdr.Count > 0 && block.Num != w.hdr.To+1 {
+		return ErrInvalidBlockNum
+	}
+	data := block.Encode()
+	var sz [binary.MaxVarintLen64]byte
+	rec := append(sz[:binary.PutUvarint(sz[:], uint64(len(data)))], data...)
+	if _, err := w.buf.Write(rec); err != nil {
+		return err
+	}
+	w.hash.Write(rec)
+	if w.hdr.Count == 0 {
+		w.hdr.From = block.Num
+	}
+	w.hdr.To = block.Num
+	w.hdr.Count++
+	return nil
+}
+
+// Header returns actual file header
+func (w *Writer) Header() Header {
+	h := w.hdr
+	h.Checksum = w.hash.Sum(nil)
+	return h
+}
+
+// Close flushes records and rewrites file header with blocks range and checksum
+func (w *Writer) Close() (err error) {
+	if err = w.buf.Flush(); err != nil {
+		return
+	}
+	if _, err = w.w.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	hdr := w.Header()
+	_, err = w.w.Write(hdr.encode())
+	return
+}
+
+//------------------ reader ------------------------
+
+type Reader struct {
+	r    *bufio.Reader
+	hash hash.Hash
+	hdr  Header
+	cnt  uint64
+}
+
+// NewReader reads file header
+func NewReader(r io.Reader) (*Reader, error) {
+	fr := &Reader{
+		r:    bufio.NewReader(r),
+		hash: sha3.New256(),
+	}
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(fr.r, buf); err != nil {
+		return nil, ErrInvalidFormat
+	}
+	if err := fr.hdr.decode(buf); err != nil {
+		return nil, err
+	}
+	return fr, nil
+}
+
+func (r *Reader) Header() Header {
+	return r.hdr
+}
+
+func (r *Reader) readRecord() (data []byte, err error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return nil, r.checkEOF()
+	} else if err != nil || size > maxRecordSize {
+		return nil, ErrInvalidFormat
+	}
+	data = make([]byte, size)
+	if _, err = io.ReadFull(r.r, data); err != nil {
+		return nil, ErrInvalidFormat
+	}
+	var sz [binary.MaxVarintLen64]byte
+	r.hash.Write(sz[:binary.PutUvarint(sz[:], size)])
+	r.hash.Write(data)
+	r.cnt++
+	return
+}
+
+func (r *Reader) checkEOF() error {
+	if r.cnt != r.hdr.Count || !bytes.Equal(r.hash.Sum(nil), r.hdr.Checksum) {
+		return ErrInvalidChecksum
+	}
+	return io.EOF
+}
+
+// ReadBlock returns next block of file or io.EOF at the end of file.
+// The checksum is verified at the end of file (before returning io.EOF).
+func (r *Reader) ReadBlock() (*blockchain.Block, error) {
+	data, err := r.readRecord()
+	if err != nil {
+		return nil, err
+	}
+	block := new(blockchain.Block)
+	if err = block.Decode(data); err != nil {
+		return nil, err
+	}
+	if block.ChainID != r.hdr.ChainID || block.Network != r.hdr.Network {
+		return nil, ErrInvalidChainID
+	}
+	if block.Num != r.hdr.From+r.cnt-1 {
+		return nil, ErrInvalidBlockNum
+	}
+	return block, nil
+}
+
+// Verify reads all records and verifies count of blocks and checksum without decoding of blocks
+func (r *Reader) Verify() error {
+	for {
+		if _, err := r.readRecord(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package chainfile
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/stretchr/testify/assert"
+)
+
+func testBlocks(from, to uint64) (blocks []*blockchain.Block) {
+	for num := from; num <= to; num++ {
+		blocks = append(blocks, blockchain.NewBlock(&blockchain.BlockHeader{
+			Network:   blockchain.NetworkTest,
+			ChainID:   1,
+			Num:       num,
+			Timestamp: 1500000000e6 + int64(num),
+			PrevHash:  []byte("0123456789abcdef0123456789abcdef"),
+		}, nil))
+	}
+	return
+}
+
+func writeTestFile(t *testing.T, blocks []*blockchain.Block) *os.File {
+	f, err := ioutil.TempFile("", "chainfile")
+	assert.NoError(t, err)
+
+	w, err := NewWriter(f, blockchain.NetworkTest, 1)
+	assert.NoError(t, err)
+	for _, b := range blocks {
+		assert.NoError(t, w.WriteBlock(b))
+	}
+	assert.NoError(t, w.Close())
+
+	_, err = f.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	return f
+}
+
+func TestReader_ReadBlock(t *testing.T) {
+	blocks := testBlocks(10, 14)
+	f := writeTestFile(t, blocks)
+	defer os.Remove(f.Name())
+
+	r, err := NewReader(f)
+	assert.NoError(t, err)
+	hdr := r.Header()
+	assert.EqualValues(t, 10, hdr.From)
+	assert.EqualValues(t, 14, hdr.To)
+	assert.EqualValues(t, 5, hdr.Count)
+	assert.EqualValues(t, 1, hdr.ChainID)
+
+	var res []*blockchain.Block
+	for {
+		b, err := r.ReadBlock()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		res = append(res, b)
+	}
+	assert.Equal(t, len(blocks), len(res))
+	for i := range blocks {
+		assert.Equal(t, blocks[i].Encode(), res[i].Encode())
+	}
+}
+
+func TestReader_Verify_fail(t *testing.T) {
+	f := writeTestFile(t, testBlocks(1, 3))
+	defer os.Remove(f.Name())
+
+	// corrupt last byte of file
+	st, _ := f.Stat()
+	_, err := f.WriteAt([]byte{0xff}, st.Size()-1)
+	assert.NoError(t, err)
+
+	r, err := NewReader(f)
+	assert.NoError(t, err)
+	assert.Equal(t, ErrInvalidChecksum, r.Verify())
+}
+
+func TestWriter_WriteBlock_invalidNum(t *testing.T) {
+	f, _ := ioutil.TempFile("", "chainfile")
+	defer os.Remove(f.Name())
+
+	w, _ := NewWriter(f, blockchain.NetworkTest, 1)
+	blocks := testBlocks(1, 3)
+
+	assert.NoError(t, w.WriteBlock(blocks[0]))
+	assert.Equal(t, ErrInvalidBlockNum, w.WriteBlock(blocks[2]))
+}
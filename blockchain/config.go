@@ -3,14 +3,27 @@ package blockchain
 import (
 	"flag"
 	"os"
+	"time"
 )
 
 type Config struct {
-	NetworkID      int    // 0-work, 1-test
-	ChainID        uint64 // blockchain ID
-	DataDir        string //
-	VerifyTxsLevel int    // by default verify only block-headers
-	VacuumDB       bool   //
+	NetworkID      int      // 0-work, 1-test
+	ChainID        uint64   // blockchain ID
+	DataDir        string   //
+	VerifyTxsLevel int      // by default verify only block-headers
+	VacuumDB       bool     //
+	PruneBlocks    uint64   // keep per-change balance history of last N blocks (0 - keep full history)
+	Consensus      int      // 0 - blocks are signed by master key; 1 - proof-of-authority
+	Genesis        *Genesis // nil - default genesis
+
+	SlotTimeout time.Duration // proof-of-authority: time after which leadership of block moves to the next validator (0 - never)
+
+	// consensus rules of emission are set by genesis only (see Genesis.Apply)
+	PoSRate        int64         // annual rate of proof-of-stake minting in basis points (0 - minting is rejected)
+	PoSMinStakeAge time.Duration // min age of stake for proof-of-stake minting
+
+	EmissionSchedule  EmissionSchedule // rates and caps of emission (nil - rates and caps are not verified)
+	EmissionDeltaFrom uint64           // block num since which delta of primary emission is verified by source values (0 - not verified)
 }
 
 const (
@@ -27,12 +40,17 @@ func NewConfig() *Config {
 		ChainID:        1,
 		VerifyTxsLevel: VerifyTxLevel1,
 		VacuumDB:       false,
+		PoSMinStakeAge: 30 * 24 * time.Hour,
+		SlotTimeout:    defaultSlotTimeout,
 		DataDir:        os.Getenv("HOME") + "/likecd.db",
 	}
 	flag.IntVar(&cfg.NetworkID, "network-id", cfg.NetworkID, "Network ID (0 - work network; 1 - test network")
 	flag.Uint64Var(&cfg.ChainID, "chain-id", cfg.ChainID, "Chain ID")
 	flag.IntVar(&cfg.VerifyTxsLevel, "verify-level", cfg.VerifyTxsLevel, "Verify tx level (0 - only block headers; 1 - each tx-state)")
+	flag.IntVar(&cfg.Consensus, "consensus", cfg.Consensus, "Consensus (0 - blocks are signed by master key; 1 - proof-of-authority by validator set)")
+	flag.DurationVar(&cfg.SlotTimeout, "slot-timeout", cfg.SlotTimeout, "Proof-of-authority: time after which leadership of block moves to the next validator (0 - never)")
 	flag.BoolVar(&cfg.VacuumDB, "vacuum", cfg.VacuumDB, "Vacuum DB after start")
+	flag.Uint64Var(&cfg.PruneBlocks, "prune", cfg.PruneBlocks, "Pruning mode: keep balance history of last N blocks (0 - keep full history)")
 	flag.StringVar(&cfg.DataDir, "db", cfg.DataDir, "Database dir")
 	return cfg
 }
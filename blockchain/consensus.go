@@ -0,0 +1,58 @@
+package blockchain
+
+import (
+	"time"
+
+	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/crypto"
+)
+
+// Consensus schemes
+const (
+	ConsensusMaster = 0 // blocks are signed by config.MasterPublicKey
+	ConsensusPoA    = 1 // proof-of-authority: blocks are signed by validators in turn (round-robin)
+)
+
+const (
+	defaultSlotTimeout = 10 * time.Second
+	maxBlockTimeDrift  = 5 * time.Second // max difference of block timestamp from local time of node (see VerifyHeader)
+)
+
+// Leader returns validator authorized to sign block #num in the slot.
+// Validators are sorted by address; the leader is chosen by round-robin.
+// Slot is the count of slot timeouts passed since the previous block (see Slot):
+// if the leader is offline, leadership moves to the next validator.
+func Leader(validators []crypto.Address, num, slot uint64) crypto.Address {
+	if len(validators) == 0 {
+		return crypto.NilAddress
+	}
+	return validators[(num+slot)%uint64(len(validators))]
+}
+
+// Slot returns the count of slot timeouts passed from timestamp of the previous block (prevTs) till ts
+func Slot(cfg *Config, prevTs, ts int64) uint64 {
+	timeout := int64(cfg.SlotTimeout / time.Microsecond)
+	if timeout <= 0 || ts <= prevTs {
+		return 0
+	}
+	return uint64((ts - prevTs) / timeout)
+}
+
+// VerifyMiner verifies that miner of block is authorized to sign the block by validator set of the previous state.
+// pre is the header of the previous block; the slot of the block is defined by the timestamps of the headers.
+// The master key is required if consensus is not PoA or validator set is empty.
+func (b *BlockHeader) VerifyMiner(cfg *Config, validators []crypto.Address, pre *BlockHeader) error {
+	if b.Miner.Empty() {
+		return ErrEmptyMinerKey
+	}
+	if cfg.Consensus != ConsensusPoA || len(validators) == 0 {
+		if !b.Miner.Equal(config.MasterPublicKey) {
+			return ErrInvalidMinerKey
+		}
+		return nil
+	}
+	if b.Miner.Address() != Leader(validators, b.Num, Slot(cfg, pre.Timestamp, b.Timestamp)) {
+		return ErrInvalidMinerKey
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	validator1 = crypto.NewPrivateKeyBySecret("validator-1")
+	validator2 = crypto.NewPrivateKeyBySecret("validator-2")
+)
+
+func TestLeader(t *testing.T) {
+	validators := []crypto.Address{validator1.PublicKey.Address(), validator2.PublicKey.Address()}
+
+	assert.Equal(t, validators[0], Leader(validators, 10, 0))
+	assert.Equal(t, validators[1], Leader(validators, 11, 0))
+	assert.Equal(t, validators[1], Leader(validators, 10, 1))
+	assert.Equal(t, crypto.NilAddress, Leader(nil, 11, 0))
+}
+
+func TestSlot(t *testing.T) {
+	cfg := &Config{SlotTimeout: 10 * time.Second}
+
+	assert.Equal(t, uint64(0), Slot(cfg, 100e6, 109e6))
+	assert.Equal(t, uint64(1), Slot(cfg, 100e6, 110e6))
+	assert.Equal(t, uint64(3), Slot(cfg, 100e6, 135e6))
+	assert.Equal(t, uint64(0), Slot(&Config{}, 100e6, 135e6))
+}
+
+func TestBlockHeader_VerifyMiner(t *testing.T) {
+	cfg := &Config{Consensus: ConsensusPoA, SlotTimeout: 10 * time.Second}
+	validators := []crypto.Address{validator1.PublicKey.Address(), validator2.PublicKey.Address()}
+	pre := &BlockHeader{Num: 9, Timestamp: 100e6}
+
+	assert.NoError(t, (&BlockHeader{Num: 10, Timestamp: 101e6, Miner: validator1.PublicKey}).VerifyMiner(cfg, validators, pre))
+	assert.Equal(t, ErrInvalidMinerKey, (&BlockHeader{Num: 11, Timestamp: 101e6, Miner: validator1.PublicKey}).VerifyMiner(cfg, validators, pre))
+}
+
+func TestBlockHeader_VerifyMiner_slotTimeout(t *testing.T) {
+	cfg := &Config{Consensus: ConsensusPoA, SlotTimeout: 10 * time.Second}
+	validators := []crypto.Address{validator1.PublicKey.Address(), validator2.PublicKey.Address()}
+	pre := &BlockHeader{Num: 9, Timestamp: 100e6}
+
+	// leader of block #10 (validator1) is offline; leadership moves to validator2 after the slot timeout
+	assert.Equal(t, ErrInvalidMinerKey, (&BlockHeader{Num: 10, Timestamp: 105e6, Miner: validator2.PublicKey}).VerifyMiner(cfg, validators, pre))
+	assert.NoError(t, (&BlockHeader{Num: 10, Timestamp: 112e6, Miner: validator2.PublicKey}).VerifyMiner(cfg, validators, pre))
+	assert.Equal(t, ErrInvalidMinerKey, (&BlockHeader{Num: 10, Timestamp: 112e6, Miner: validator1.PublicKey}).VerifyMiner(cfg, validators, pre))
+}
+
+func TestBlockHeader_VerifyMiner_emptyValidatorSet(t *testing.T) {
+	cfg := &Config{Consensus: ConsensusPoA}
+	pre := &BlockHeader{Num: 9}
+
+	assert.NoError(t, (&BlockHeader{Num: 10, Miner: config.MasterPublicKey}).VerifyMiner(cfg, nil, pre))
+	assert.Equal(t, ErrInvalidMinerKey, (&BlockHeader{Num: 10, Miner: validator1.PublicKey}).VerifyMiner(cfg, nil, pre))
+}
+
+func TestBlockHeader_VerifyHeader_futureSlot(t *testing.T) {
+	cfg := &Config{Consensus: ConsensusPoA, SlotTimeout: 10 * time.Second}
+	validators := []crypto.Address{validator1.PublicKey.Address(), validator2.PublicKey.Address()}
+	pre := &BlockHeader{Num: 9, Timestamp: Timestamp()}
+
+	// validator2 forges timestamp of the next slot to take leadership of block #10 from validator1
+	b := &BlockHeader{Num: 10, Timestamp: pre.Timestamp + 10e6, PrevHash: pre.Hash(), Miner: validator2.PublicKey}
+	b.Sig = validator2.Sign(b.sigHash())
+
+	assert.NoError(t, b.VerifyMiner(cfg, validators, pre))
+	assert.Equal(t, ErrBlockFromFuture, b.VerifyHeader(pre, cfg))
+}
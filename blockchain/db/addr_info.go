@@ -9,14 +9,15 @@ import (
 )
 
 type AddressInfo struct {
-	Address     string       `json:"address"`      // original address
-	AddressHex  string       `json:"addr_hex"`     //
-	MemoAddress string       `json:"address_memo"` // address+memo
-	Memo        string       `json:"memo"`         // memo in hex
-	Balance     bignum.Int   `json:"balance"`      // balance on address (not memo address)
-	Asset       assets.Asset `json:"asset"`        //
-	LastTx      hex.Bytes    `json:"last_tx"`      // last tx of memo address
-	User        *object.User `json:"user"`         // user associated with address
+	Address     string       `json:"address"`            // original address
+	AddressHex  string       `json:"addr_hex"`           //
+	MemoAddress string       `json:"address_memo"`       // address+memo
+	Memo        string       `json:"memo"`               // memo in hex
+	Balance     bignum.Int   `json:"balance"`            // balance on address (not memo address)
+	Asset       assets.Asset `json:"asset"`              //
+	LastTx      hex.Bytes    `json:"last_tx"`            // last tx of memo address
+	User        *object.User `json:"user"`               // user associated with address
+	AtBlock     uint64       `json:"at_block,omitempty"` // block height of historical balance
 }
 
 func (s *BlockchainStorage) AddressInfo(addr crypto.Address, memo uint64, asset assets.Asset) (inf AddressInfo, err error) {
@@ -46,3 +47,26 @@ func (s *BlockchainStorage) AddressInfo(addr crypto.Address, memo uint64, asset
 
 	return
 }
+
+// AddressInfoAt returns address info with balance and last tx at the block height blockNum
+func (s *BlockchainStorage) AddressInfoAt(addr crypto.Address, memo uint64, asset assets.Asset, blockNum uint64) (inf AddressInfo, err error) {
+	if inf, err = s.AddressInfo(addr, memo, asset); err != nil {
+		return
+	}
+	bal, tx, err := s.BalanceAt(addr, asset, blockNum)
+	if err != nil {
+		return
+	}
+	if memo != 0 {
+		if tx, _, err = s.QueryTransaction(asset, addr, memo, encodeTxUID(blockNum+1, 0), true); err != nil {
+			return
+		}
+	}
+	inf.Balance = bal
+	inf.LastTx = nil
+	if tx != nil {
+		inf.LastTx = tx.Hash()
+	}
+	inf.AtBlock = blockNum
+	return
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/crypto/merkle"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+var ErrTxHasNoCrossChainOuts = errors.New("tx has no cross-chain outs")
+
+// TxClaim returns claim-object for cross-chain outs of transaction:
+// header of the block, transaction and merkle proof of tx inclusion into the block.
+// The claim can be sent to the destination chain as object.Claim transaction.
+func (s *BlockchainStorage) TxClaim(tx *blockchain.Transaction) (*object.Claim, error) {
+	if !hasCrossChainOuts(tx) {
+		return nil, ErrTxHasNoCrossChainOuts
+	}
+	header, err := s.BlockHeader(tx.BlockNum())
+	if err != nil {
+		return nil, err
+	}
+	txs, err := s.BlockTxs(tx.BlockNum())
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([][]byte, len(txs))
+	for i, t := range txs {
+		hashes[i] = t.TxStHash()
+	}
+	proof, _ := merkle.Proof(hashes, tx.BlockIdx())
+	return &object.Claim{
+		Header: header,
+		SrcTx:  tx,
+		Proof:  proof,
+	}, nil
+}
+
+func hasCrossChainOuts(tx *blockchain.Transaction) bool {
+	for _, v := range tx.StateUpdates {
+		if v.ChainID != tx.ChainID {
+			return true
+		}
+	}
+	return false
+}
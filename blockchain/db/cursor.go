@@ -0,0 +1,59 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/denisskin/bin"
+)
+
+// Cursor is the position of paging: the last fetched index record
+type Cursor struct {
+	TxUID  uint64 // txUID, blockNum or txID of the last fetched record
+	StIdx  int    // index of state update of the last fetched record
+	Filter uint64 // hash of query params
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func (c *Cursor) Encode() []byte {
+	return bin.Encode(
+		0, // ver
+		c.TxUID,
+		c.StIdx,
+		c.Filter,
+	)
+}
+
+func (c *Cursor) Decode(data []byte) error {
+	return bin.Decode(data,
+		new(int),
+		&c.TxUID,
+		&c.StIdx,
+		&c.Filter,
+	)
+}
+
+// String returns opaque string representation of cursor
+func (c *Cursor) String() string {
+	if c == nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(c.Encode())
+}
+
+// ParseCursor parses cursor by string; returns nil for empty string
+func ParseCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := new(Cursor)
+	if err = c.Decode(data); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
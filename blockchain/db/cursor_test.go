@@ -0,0 +1,30 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCursor(t *testing.T) {
+	c := &Cursor{TxUID: encodeTxUID(123456, 7), StIdx: 2, Filter: 0xfedcba9876543210}
+
+	c1, err := ParseCursor(c.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, c, c1)
+}
+
+func TestParseCursor_fail(t *testing.T) {
+	c, err := ParseCursor("invalid cursor")
+
+	assert.Nil(t, c)
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func TestParseCursor_empty(t *testing.T) {
+	c, err := ParseCursor("")
+
+	assert.Nil(t, c)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+var (
+	errIncorrectEmissionDelta = errors.New("incorrect delta of primary emission")
+	errIncorrectEmissionRate  = errors.New("rate of emission does not match emission schedule")
+	errEmissionCapExceeded    = errors.New("emission of block exceeds cap of emission schedule")
+	errIncorrectReferral      = errors.New("incorrect referral reward")
+	errUnknownSourceValue     = errors.New("unknown previous value of source (node has been restored from snapshot)")
+)
+
+// verifyPrimaryEmission verifies by opened db-transaction that
+// delta of each out of primary emission equals the difference of source value and the previous source value (see LastSourceData).
+// Delta is verified for blocks since Config.EmissionDeltaFrom only.
+func (s *BlockchainStorage) verifyPrimaryEmission(tr *goldb.Transaction, block *blockchain.Block, emission *object.Emission) {
+	if s.Cfg.EmissionDeltaFrom == 0 || block.Num < s.Cfg.EmissionDeltaFrom {
+		return
+	}
+	sources := map[string]bool{}
+	for _, out := range emission.Outs {
+		if sources[out.SourceID] { // source value can be changed once by emission
+			tr.Fail(invalidBlock(errIncorrectEmissionDelta))
+		}
+		sources[out.SourceID] = true
+
+		prevValue, _, ok, err := lastSourceData(tr, emission.Asset, out.SourceID)
+		if err != nil {
+			tr.Fail(err)
+		}
+		if !ok { // value of source without emissions is 0; it is unknown if node has been restored from snapshot
+			if restored, err := isRestoredFromSnapshot(tr); err != nil {
+				tr.Fail(err)
+			} else if restored { // fail closed: the delta can not be verified without history of source
+				tr.Fail(errUnknownSourceValue)
+			}
+		}
+		if out.Delta != out.SourceValue-prevValue {
+			tr.Fail(invalidBlock(errIncorrectEmissionDelta))
+		}
+	}
+}
+
+// verifyEmissionSchedule verifies that rate of emission follows the emission schedule
+// and total emission of block (emitted) does not exceed cap of the schedule.
+// Every emission of block is counted against the cap (whatever comment it has);
+// proof-of-stake minting pays rewards in nano-coins and its rate is not compared with the schedule.
+func (s *BlockchainStorage) verifyEmissionSchedule(tr *goldb.Transaction, block *blockchain.Block, emission *object.Emission, emitted *bignum.Int) {
+	period := s.Cfg.EmissionSchedule.PeriodAt(block.Timestamp)
+	if period == nil {
+		return
+	}
+	if !emission.IsPoSMinting() && !emission.Rate.Equal(period.Rate) {
+		tr.Fail(invalidBlock(errIncorrectEmissionRate))
+	}
+	emitted.Increment(emission.TotalAmount())
+	if period.MaxPerBlock.Sign() > 0 && emitted.Cmp(period.MaxPerBlock) > 0 {
+		tr.Fail(invalidBlock(errEmissionCapExceeded))
+	}
+}
+
+// verifyReferralReward verifies that each out of referral reward pays a positive amount of coins to the user who has invited users
+func verifyReferralReward(tr *goldb.Transaction, emission *object.Emission) {
+	if !emission.Asset.IsCoin() {
+		tr.Fail(invalidBlock(errIncorrectReferral))
+	}
+	for _, out := range emission.Outs {
+		if out.Delta <= 0 {
+			tr.Fail(invalidBlock(errIncorrectReferral))
+		}
+		if n, err := tr.GetNumRows(goldb.NewQuery(dbIdxInvites, out.Address.ID()).Limit(1)); err != nil {
+			tr.Fail(err)
+		} else if n == 0 {
+			tr.Fail(invalidBlock(errIncorrectReferral))
+		}
+	}
+}
+
+// isRestoredFromSnapshot returns true if node has been restored from snapshot (history before snapshot is absent)
+func isRestoredFromSnapshot(c dbContext) (bool, error) {
+	var snapshotNum uint64
+	_, err := c.GetVar(goldb.Key(dbTabSchema, schemaKeySnapshot), &snapshotNum)
+	return snapshotNum != 0, err
+}
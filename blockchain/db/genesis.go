@@ -0,0 +1,115 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/crypto/patricia"
+)
+
+var (
+	errDBIsNotEmpty     = errors.New("db is not empty")
+	errUsernameReserved = errors.New("username is reserved")
+)
+
+// InitGenesis writes genesis block (initial state and reserved usernames) to empty db
+// and applies network params and keys of genesis to the node (see applyGenesis)
+func (s *BlockchainStorage) InitGenesis(g *blockchain.Genesis) error {
+	if err := g.Verify(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	s.mxW.Lock()
+	defer s.mxW.Unlock()
+
+	if s.lastBlock.Num > 0 || s.Cfg.Genesis != nil {
+		return errDBIsNotEmpty
+	}
+	var stat *Statistic
+	err = s.db.Exec(func(tr *goldb.Transaction) {
+		tr.Put(goldb.Key(dbTabGenesis), data)
+		stat = putGenesisState(tr, g)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.applyGenesis(g)
+
+	s.mxR.Lock()
+	s.lastBlock = blockchain.NewBlock(blockchain.GenesisBlockHeader(s.Cfg), nil)
+	s.stat = stat
+	s.mxR.Unlock()
+	return nil
+}
+
+// putGenesisState puts initial state, its statistic and reserved usernames of genesis by opened db-transaction
+func putGenesisState(tr *goldb.Transaction, g *blockchain.Genesis) *Statistic {
+	stat := &Statistic{}
+	stateTree := patricia.NewSubTree(tr, goldb.Key(dbTabStateTree))
+	for _, v := range g.State() {
+		stateTree.Put(v.StateKey(), v.Balance.Bytes())
+		tr.PutVar(goldb.Key(dbIdxBalances, v.Asset, v.Address), v.Balance)
+		updateRichList(tr, v.Asset, v.Address, bignum.Int{}, v.Balance)
+		stat.IncSupplyStat(v.Asset, bignum.Int{}, v.Balance)
+	}
+	tr.PutVar(goldb.Key(dbTabStat, g.Timestamp, uint64(0)), stat)
+
+	for _, nick := range g.Usernames {
+		tr.PutID(goldb.Key(dbIdxReservedNicks, nick), 1)
+	}
+	return stat
+}
+
+// loadGenesis applies network params and keys of genesis stored in db to the node (see applyGenesis)
+func (s *BlockchainStorage) loadGenesis() error {
+	data, err := s.db.Get(goldb.Key(dbTabGenesis))
+	if err != nil || data == nil {
+		return err
+	}
+	g, err := blockchain.ParseGenesis(data)
+	if err != nil {
+		return err
+	}
+	s.applyGenesis(g)
+	return nil
+}
+
+// applyGenesis applies network params of genesis to the node configuration and sets master and emission keys of genesis as node keys
+func (s *BlockchainStorage) applyGenesis(g *blockchain.Genesis) {
+	config.MasterPublicKey, config.EmissionPublicKey = g.Apply(s.Cfg)
+}
+
+// genesisBalance returns initial balance of address by genesis of node.
+// Returns ErrHistoryPruned if node has been restored from snapshot (the balance could be changed before the snapshot)
+func (s *BlockchainStorage) genesisBalance(asset assets.Asset, addr crypto.Address) (balance bignum.Int, err error) {
+	if restored, err := isRestoredFromSnapshot(s.db); err != nil {
+		return balance, err
+	} else if restored {
+		return balance, ErrHistoryPruned
+	}
+	if g := s.Cfg.Genesis; g != nil {
+		for _, v := range g.State() {
+			if v.Asset.Equal(asset) && v.Address == addr {
+				return v.Balance, nil
+			}
+		}
+	}
+	return
+}
+
+// isReservedNick returns true if username is reserved by genesis
+func isReservedNick(tr *goldb.Transaction, nick string) bool {
+	id, _ := tr.GetID(goldb.Key(dbIdxReservedNicks, nick))
+	return id != 0
+}
@@ -12,6 +12,16 @@ type Info struct {
 	Stat      *Statistic              `json:"stat"`       //
 	LastBlock *blockchain.BlockHeader `json:"last_block"` //
 	Mempool   mempool.Info            `json:"mempool"`    //
+	Sync      *SyncInfo               `json:"sync"`       // nil - replication is off
+}
+
+// SyncInfo is the progress of blockchain replication
+type SyncInfo struct {
+	Syncing      bool    `json:"syncing"`        // initial sync is in progress
+	Current      uint64  `json:"current"`        // number of the last committed block
+	Target       uint64  `json:"target"`         // number of the best block of upstream nodes
+	BlocksPerSec float64 `json:"blocks_per_sec"` // sync speed
+	ETA          int64   `json:"eta"`            // estimated time of sync in seconds
 }
 
 func (s *BlockchainStorage) Info() (inf Info, err error) {
@@ -20,5 +30,13 @@ func (s *BlockchainStorage) Info() (inf Info, err error) {
 	inf.Stat = s.Totals()
 	inf.LastBlock = s.LastBlock().BlockHeader
 	inf.Mempool = s.Mempool.Info()
+	if s.syncInfo != nil {
+		inf.Sync = s.syncInfo()
+	}
 	return
 }
+
+// SetSyncInfo sets provider of progress of blockchain replication
+func (s *BlockchainStorage) SetSyncInfo(fn func() *SyncInfo) {
+	s.syncInfo = fn
+}
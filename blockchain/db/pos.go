@@ -0,0 +1,104 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+var (
+	errIncorrectPoSMinting = errors.New("incorrect proof-of-stake minting")
+	errUnknownStakeAge     = errors.New("unknown stake age (node has been restored from snapshot)")
+)
+
+// StakeInfo is the stake of address and its accrued reward of proof-of-stake minting
+type StakeInfo struct {
+	Address    crypto.Address `json:"address"`     //
+	Asset      assets.Asset   `json:"asset"`       //
+	Stake      bignum.Int     `json:"stake"`       // balance of address
+	StakeSince int64          `json:"stake_since"` // timestamp of the last change of balance in µsec (0 - unknown)
+	Reward     bignum.Int     `json:"reward"`      // accrued and unminted reward at the current time
+}
+
+// dbContext is the common reading interface of db-storage and db-transaction
+type dbContext interface {
+	GetVar(key []byte, v interface{}) (bool, error)
+	Fetch(q *goldb.Query, fn func(goldb.Record) error) error
+}
+
+// StakeInfo returns stake of address and the reward which can be minted now
+func (s *BlockchainStorage) StakeInfo(addr crypto.Address, asset assets.Asset) (inf *StakeInfo, err error) {
+	inf = &StakeInfo{Address: addr, Asset: asset}
+	if _, err = s.db.GetVar(goldb.Key(dbIdxBalances, asset, addr), &inf.Stake); err != nil {
+		return
+	}
+	now := blockchain.Timestamp()
+	since, ok, err := s.stakeSince(s.db, asset, addr, now)
+	if err != nil || !ok {
+		return
+	}
+	inf.StakeSince = since
+	inf.Reward = blockchain.PoSReward(s.Cfg, inf.Stake, since, now)
+	return
+}
+
+// stakeSince returns timestamp of the last change of balance by index dbIdxAssetAddr.
+// Balances without history are balances of genesis; ok is false if history is unknown (node has been restored from snapshot).
+// Balance changed by the block which is being applied (its header is not stored yet) has timestamp at (stake age is 0).
+func (s *BlockchainStorage) stakeSince(c dbContext, asset assets.Asset, addr crypto.Address, at int64) (ts int64, ok bool, err error) {
+	var txUID uint64
+	err = c.Fetch(goldb.NewQuery(dbIdxAssetAddr, asset, addr).Last(), func(rec goldb.Record) error {
+		rec.MustDecodeKey(&asset, &addr, &txUID)
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	if txUID != 0 {
+		blockNum, _ := decodeTxUID(txUID)
+		h := new(blockchain.BlockHeader)
+		if ok, err = c.GetVar(goldb.Key(dbTabHeaders, blockNum), h); err != nil {
+			return
+		} else if !ok {
+			return at, true, nil
+		}
+		return h.Timestamp, true, nil
+	}
+	if restored, err := isRestoredFromSnapshot(c); err != nil || restored {
+		return 0, false, err
+	}
+	return blockchain.GenesisBlockHeader(s.Cfg).Timestamp, true, nil
+}
+
+// verifyPoSMinting verifies that each out of minting emission pays exactly the reward of stake at the time of block
+func (s *BlockchainStorage) verifyPoSMinting(tr *goldb.Transaction, block *blockchain.Block, emission *object.Emission) {
+	if !emission.Asset.IsCoin() {
+		tr.Fail(invalidBlock(errIncorrectPoSMinting))
+	}
+	minted := map[crypto.Address]bool{}
+	for _, out := range emission.Outs {
+		if minted[out.Address] { // reward of stake can be minted once by block
+			tr.Fail(invalidBlock(errIncorrectPoSMinting))
+		}
+		minted[out.Address] = true
+
+		var stake bignum.Int
+		tr.GetVar(goldb.Key(dbIdxBalances, emission.Asset, out.Address), &stake)
+		since, ok, err := s.stakeSince(tr, emission.Asset, out.Address, block.Timestamp)
+		if err != nil {
+			tr.Fail(err)
+		}
+		if !ok { // fail closed: the minting can not be verified without history of stake
+			tr.Fail(errUnknownStakeAge)
+		}
+		reward := blockchain.PoSReward(s.Cfg, stake, since, block.Timestamp)
+		if reward.Sign() <= 0 || !reward.Equal(emission.Amount(out.Delta)) {
+			tr.Fail(invalidBlock(errIncorrectPoSMinting))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/crypto"
+)
+
+// pruneBatchSize is the max count of deleted index-records by one state change
+const pruneBatchSize = 1000
+
+var ErrHistoryPruned = errors.New("history of address has been pruned")
+
+// pruneHistory deletes per-change balance rows of (asset, addr[, memo]) older than Cfg.PruneBlocks blocks.
+// It is called on each state change, so history of an address is pruned incrementally, when the address is changed.
+// Rows of the asset feed (dbIdxAsset) are deleted together with per-change rows of the address,
+// except rows of name-assets (the last row of name defines the name owner).
+// The newest deleted row is marked in dbIdxPruned.
+//
+// State-tree needs no pruning: patricia nodes are stored by the node path, so the tree holds only actual nodes.
+func (s *BlockchainStorage) pruneHistory(tr *goldb.Transaction, blockNum uint64, asset assets.Asset, addr crypto.Address, memo uint64) {
+	horizon := s.pruneHorizon(blockNum)
+	if horizon == 0 {
+		return
+	}
+
+	var q *goldb.Query
+	if memo == 0 {
+		q = goldb.NewQuery(dbIdxAssetAddr, asset, addr)
+	} else {
+		q = goldb.NewQuery(dbIdxAssetAddrMemo, asset, addr, memo)
+	}
+	var keys [][]byte
+	var prunedTxUID uint64
+	err := tr.Fetch(q.Limit(pruneBatchSize), func(rec goldb.Record) error {
+		var txUID, _memo uint64
+		var stIdx int
+		if memo == 0 {
+			rec.MustDecodeKey(&asset, &addr, &txUID, &stIdx)
+			if txUID < horizon && !asset.IsName() {
+				keys = append(keys, goldb.Key(dbIdxAsset, asset, txUID, stIdx, addr))
+			}
+		} else {
+			rec.MustDecodeKey(&asset, &addr, &_memo, &txUID)
+		}
+		if txUID >= horizon {
+			return goldb.Break
+		}
+		keys = append(keys, rec.Key)
+		prunedTxUID = txUID
+		return nil
+	})
+	if err != nil {
+		tr.Fail(err)
+	}
+	if len(keys) == 0 {
+		return
+	}
+	for _, key := range keys {
+		tr.Delete(key)
+	}
+	tr.PutID(goldb.Key(dbIdxPruned, asset, addr, memo), prunedTxUID)
+}
+
+// pruneHorizon returns txUID before which history rows are pruned at block blockNum (0 - history is not pruned)
+func (s *BlockchainStorage) pruneHorizon(blockNum uint64) uint64 {
+	if s.Cfg.PruneBlocks == 0 || blockNum <= s.Cfg.PruneBlocks {
+		return 0
+	}
+	return encodeTxUID(blockNum-s.Cfg.PruneBlocks, 0)
+}
+
+// PrunedTxUID returns txUID of the newest pruned history record of (asset, addr[, memo]); 0 - history is not pruned
+func (s *BlockchainStorage) PrunedTxUID(asset assets.Asset, addr crypto.Address, memo uint64) (uint64, error) {
+	return s.db.GetID(goldb.Key(dbIdxPruned, asset, addr, memo))
+}
+
+// CheckHistory returns ErrHistoryPruned if the query of address history from offset hits pruned records
+func (s *BlockchainStorage) CheckHistory(asset assets.Asset, addr crypto.Address, memo, offset uint64, orderDesc bool) error {
+	prunedTxUID, err := s.PrunedTxUID(asset, addr, memo)
+	if err != nil || prunedTxUID == 0 {
+		return err
+	}
+	if orderDesc && offset != 0 && offset <= prunedTxUID || !orderDesc && offset < prunedTxUID {
+		return ErrHistoryPruned
+	}
+	return nil
+}
+
+// CheckAssetFeed returns ErrHistoryPruned if the query of asset feed from offset hits rows before the prune horizon.
+// Rows of name-assets are not pruned.
+func (s *BlockchainStorage) CheckAssetFeed(asset assets.Asset, offset uint64, orderDesc bool) error {
+	horizon := s.pruneHorizon(s.LastBlock().Num)
+	if horizon == 0 || asset.IsName() {
+		return nil
+	}
+	if offset < horizon && (offset != 0 || !orderDesc) {
+		return ErrHistoryPruned
+	}
+	return nil
+}
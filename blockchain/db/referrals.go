@@ -0,0 +1,117 @@
+package db
+
+import (
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/commons/hex"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+// maxReferralTreeNodes is the max count of users in referral tree
+const maxReferralTreeNodes = 10000
+
+type ReferralNode struct {
+	UserID   hex.Uint64      `json:"user_id"`  //
+	Nick     string          `json:"nick"`     //
+	Address  crypto.Address  `json:"address"`  //
+	Invites  int64           `json:"invites"`  // count of invited users (first level)
+	Rewards  bignum.Int      `json:"rewards"`  // total referral rewards
+	Children []*ReferralNode `json:"children"` // invited users (nil - children are not fetched)
+}
+
+// ReferralRewards returns total referral_reward emission received by user
+func (s *BlockchainStorage) ReferralRewards(asset assets.Asset, userID uint64) (total bignum.Int, err error) {
+	if err = s.CheckFullIndexes(); err != nil {
+		return
+	}
+	_, err = s.db.GetVar(goldb.Key(dbIdxSrcInvites, asset, userID), &total)
+	return
+}
+
+// ReferralTree returns multi-level tree of users invited by userID.
+// depth is the count of levels; limit is the max count of users in the tree.
+func (s *BlockchainStorage) ReferralTree(asset assets.Asset, userID uint64, depth int, limit int) (root *ReferralNode, err error) {
+	if limit <= 0 || limit > maxReferralTreeNodes {
+		limit = maxReferralTreeNodes
+	}
+	tx, u, err := s.UserByID(userID)
+	if err != nil {
+		return
+	}
+	if tx == nil {
+		return nil, errUserNotFound
+	}
+	if root, err = s.referralNode(asset, tx, u); err != nil {
+		return
+	}
+	level := []*ReferralNode{root}
+	for ; depth > 0 && len(level) > 0 && limit > 0; depth-- {
+		var next []*ReferralNode
+		for _, parent := range level {
+			if parent.Invites == 0 {
+				continue
+			}
+			if limit <= 0 {
+				break
+			}
+			err = s.FetchInvitedUsers(uint64(parent.UserID), 0, int64(limit), false, func(tx *blockchain.Transaction, u *object.User) error {
+				node, err := s.referralNode(asset, tx, u)
+				if err != nil {
+					return err
+				}
+				parent.Children = append(parent.Children, node)
+				next = append(next, node)
+				limit--
+				return nil
+			})
+			if err != nil {
+				return
+			}
+		}
+		level = next
+	}
+	return
+}
+
+func (s *BlockchainStorage) referralNode(asset assets.Asset, tx *blockchain.Transaction, u *object.User) (node *ReferralNode, err error) {
+	addr := tx.SenderAddress()
+	node = &ReferralNode{
+		UserID:  hex.Uint64(addr.ID()),
+		Nick:    u.Nick,
+		Address: addr,
+	}
+	if node.Invites, err = s.countInvites(addr.ID()); err != nil {
+		return
+	}
+	node.Rewards, err = s.ReferralRewards(asset, addr.ID())
+	return
+}
+
+func (s *BlockchainStorage) countInvites(userID uint64) (int64, error) {
+	n, err := s.db.GetNumRows(goldb.NewQuery(dbIdxInvites, userID))
+	return int64(n), err
+}
+
+// putReferralRewards increments total referral rewards of receivers of referral_reward emission
+func putReferralRewards(tr *goldb.Transaction, emission *object.Emission) {
+	for _, out := range emission.Outs {
+		if out.Delta > 0 {
+			delta := emission.Amount(out.Delta)
+			tr.IncrementBig(goldb.Key(dbIdxSrcInvites, emission.Asset, out.Address.ID()), delta.BigInt())
+		}
+	}
+}
+
+// migrateReferralRewards builds totals of referral rewards by stored referral_reward emissions
+func migrateReferralRewards(s *BlockchainStorage, ver int) error {
+	return s.migrateBlocks(ver, func(tr *goldb.Transaction, block *blockchain.Block) {
+		for _, tx := range block.Txs {
+			if emission, ok := tx.TxObject().(*object.Emission); ok && emission.IsReferralReward() {
+				putReferralRewards(tr, emission)
+			}
+		}
+	})
+}
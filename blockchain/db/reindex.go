@@ -0,0 +1,174 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/log"
+	"github.com/likecoin-pro/likecoin/crypto/patricia"
+)
+
+const (
+	reindexBatchSize = 1000  // blocks in one db-transaction
+	clearBatchSize   = 10000 // deleted records in one db-transaction
+)
+
+var errReindexSnapshot = errors.New("db: reindex of db restored from snapshot is not supported (blocks before snapshot are absent)")
+
+// derivedTables returns queries on all tables which can be rebuilt from dbTabHeaders and dbTabTxs
+func derivedTables() []*goldb.Query {
+	return []*goldb.Query{
+		goldb.NewQuery(dbTabChainTree),
+		goldb.NewQuery(dbTabStateTree),
+		goldb.NewQuery(dbTabStat),
+		goldb.NewQuery(dbIdxTxID),
+		goldb.NewQuery(dbIdxAsset),
+		goldb.NewQuery(dbIdxAssetAddr),
+		goldb.NewQuery(dbIdxAssetAddrMemo),
+		goldb.NewQuery(dbIdxUsers),
+		goldb.NewQuery(dbIdxSourceTx),
+		goldb.NewQuery(dbIdxSourceAddr),
+		goldb.NewQuery(dbIdxInvites),
+		goldb.NewQuery(dbIdxSrcInvites),
+		goldb.NewQuery(dbIdxBalances),
+		goldb.NewQuery(dbIdxPruned),
+		goldb.NewQuery(dbIdxRichList),
+		goldb.NewQuery(dbIdxHolders),
+		goldb.NewQuery(dbIdxAddrSources),
+		goldb.NewQuery(dbIdxUserList),
+		goldb.NewQuery(dbIdxNickPrefix),
+		goldb.NewQuery(dbIdxCounterparty),
+	}
+}
+
+// Reindex rebuilds state-trees, statistic and all secondary indexes from stored block headers and transactions.
+// State root and chain root of each block are verified with the stored block header.
+// Progress is saved with each batch of blocks; an interrupted reindex continues from the last reindexed block.
+// DB restored from snapshot can not be reindexed.
+func (s *BlockchainStorage) Reindex() (err error) {
+	s.mxW.Lock()
+	defer s.mxW.Unlock()
+
+	if restored, err := isRestoredFromSnapshot(s.db); err != nil {
+		return err
+	} else if restored {
+		return errReindexSnapshot
+	}
+
+	maxNum := s.lastBlock.Num
+
+	var (
+		lastBlockHeader = blockchain.GenesisBlockHeader(s.Cfg)
+		blockStat       = &Statistic{}
+	)
+	if num, ok, err := s.ReindexProgress(); err != nil {
+		return err
+	} else if ok && num > 0 {
+		log.Printf("db> reindex: continue from block#%d", num)
+		if lastBlockHeader, err = s.BlockHeader(num); err != nil {
+			return err
+		}
+		if err = s.db.QueryValue(goldb.NewQuery(dbTabStat).Last(), &blockStat); err != nil {
+			return err
+		}
+	} else {
+		// save progress before clearing, so the node knows that indexes are incomplete
+		if err = s.db.Exec(func(tr *goldb.Transaction) { tr.PutVar(goldb.Key(dbTabSchema, schemaKeyReindex), uint64(0)) }); err != nil {
+			return err
+		}
+		log.Printf("db> reindex: clear indexes")
+		for _, q := range derivedTables() {
+			if err = s.clearTable(q); err != nil {
+				return err
+			}
+		}
+		if g := s.Cfg.Genesis; g != nil {
+			log.Printf("db> reindex: initial state of genesis")
+			if err = s.db.Exec(func(tr *goldb.Transaction) { blockStat = putGenesisState(tr, g) }); err != nil {
+				return err
+			}
+		}
+	}
+	for lastBlockHeader.Num < maxNum {
+		var blocks []*blockchain.Block
+		err = s.FetchBlocks(lastBlockHeader.Num, reindexBatchSize, false, func(block *blockchain.Block) error {
+			blocks = append(blocks, block)
+			return nil
+		})
+		if err != nil {
+			return
+		}
+		if len(blocks) == 0 {
+			break
+		}
+		for _, block := range blocks {
+			if err = block.Verify(lastBlockHeader, s.Cfg); err != nil {
+				return
+			}
+			lastBlockHeader = block.BlockHeader
+		}
+		if s.Cfg.VerifyTxsLevel >= blockchain.VerifyTxLevel1 {
+			if err = blockchain.VerifyTxs(s.Cfg, blockchain.BlocksTxs(blocks)); err != nil {
+				return
+			}
+		}
+		err = s.db.Exec(func(tr *goldb.Transaction) {
+			stateTree := patricia.NewSubTree(tr, goldb.Key(dbTabStateTree))
+			chainTree := patricia.NewSubTree(tr, goldb.Key(dbTabChainTree))
+			for _, block := range blocks {
+				blockStat = s.putBlock(tr, stateTree, chainTree, block, blockStat)
+			}
+			tr.PutVar(goldb.Key(dbTabSchema, schemaKeyReindex), lastBlockHeader.Num)
+		})
+		if err != nil {
+			log.Error.Printf("db> reindex: block#%d-%d Error: %v", blocks[0].Num, lastBlockHeader.Num, err)
+			return
+		}
+		log.Printf("db> reindex: block %d of %d (%.1f%%)", lastBlockHeader.Num, maxNum, float64(lastBlockHeader.Num)*100/float64(maxNum))
+	}
+
+	s.mxR.Lock()
+	s.stat = blockStat
+	s.mxR.Unlock()
+
+	// all indexes are built by actual schema
+	if err = s.setSchemaVersion(SchemaVersion()); err != nil {
+		return
+	}
+	if err = s.db.Exec(func(tr *goldb.Transaction) { tr.Delete(goldb.Key(dbTabSchema, schemaKeyReindex)) }); err != nil {
+		return
+	}
+
+	log.Printf("db> reindex: finished. blocks: %d, txs: %d", blockStat.Blocks, blockStat.Txs)
+	return
+}
+
+// ReindexProgress returns the last reindexed block num; ok is true if reindex has been started and not finished
+func (s *BlockchainStorage) ReindexProgress() (num uint64, ok bool, err error) {
+	ok, err = s.db.GetVar(goldb.Key(dbTabSchema, schemaKeyReindex), &num)
+	return
+}
+
+// clearTable deletes all records fetched by query; records are deleted by batches
+func (s *BlockchainStorage) clearTable(q *goldb.Query) error {
+	q.Limit(clearBatchSize)
+	for {
+		var keys [][]byte
+		err := s.db.Fetch(q, func(rec goldb.Record) error {
+			keys = append(keys, rec.Key)
+			return nil
+		})
+		if err != nil || len(keys) == 0 {
+			return err
+		}
+		err = s.db.Exec(func(tr *goldb.Transaction) {
+			for _, key := range keys {
+				tr.Delete(key)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
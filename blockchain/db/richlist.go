@@ -0,0 +1,156 @@
+package db
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
+)
+
+// richListBalanceSize is the fixed size of balance in key of dbIdxRichList (rows are ordered by balance)
+const richListBalanceSize = 32
+
+var errRichListBalanceTooBig = errors.New("balance is too big for rich-list index")
+
+type Holder struct {
+	Address crypto.Address `json:"address"` //
+	Balance bignum.Int     `json:"balance"` //
+}
+
+type HoldersBucket struct {
+	Min   bignum.Int `json:"min"`   // min balance of bucket
+	Max   bignum.Int `json:"max"`   // max balance of bucket (exclusive)
+	Count int64      `json:"count"` // count of holders
+}
+
+type AssetHolders struct {
+	Asset   assets.Asset     `json:"asset"`   //
+	Holders int64            `json:"holders"` // count of addresses with positive balance
+	Top     []*Holder        `json:"top"`     // top holders by balance
+	Buckets []*HoldersBucket `json:"buckets"` // distribution of holders by balance (by decimal order of balance)
+}
+
+func richListBalance(balance bignum.Int) ([]byte, error) {
+	b := balance.Bytes()
+	if len(b) > richListBalanceSize {
+		return nil, errRichListBalanceTooBig
+	}
+	buf := make([]byte, richListBalanceSize)
+	copy(buf[richListBalanceSize-len(b):], b)
+	return buf, nil
+}
+
+// holdersBucket returns decimal order of balance; bucket i contains balances in [10^i, 10^(i+1))
+func holdersBucket(balance bignum.Int) int {
+	return len(balance.String()) - 1
+}
+
+// updateRichList moves address in rich-list index and holders buckets on change of its balance
+func updateRichList(tr *goldb.Transaction, asset assets.Asset, addr crypto.Address, oldBalance, newBalance bignum.Int) {
+	if !asset.IsCoin() || oldBalance.Equal(newBalance) {
+		return
+	}
+	if oldBalance.Sign() > 0 {
+		b, err := richListBalance(oldBalance)
+		if err != nil {
+			tr.Fail(err)
+		}
+		tr.Delete(goldb.Key(dbIdxRichList, asset, b, addr))
+		tr.Increment(goldb.Key(dbIdxHolders, asset, holdersBucket(oldBalance)), -1)
+	}
+	if newBalance.Sign() > 0 {
+		b, err := richListBalance(newBalance)
+		if err != nil {
+			tr.Fail(err)
+		}
+		tr.Put(goldb.Key(dbIdxRichList, asset, b, addr), nil)
+		tr.Increment(goldb.Key(dbIdxHolders, asset, holdersBucket(newBalance)), 1)
+	}
+}
+
+// FetchRichList fetches holders of asset ordered by balance desc
+func (s *BlockchainStorage) FetchRichList(asset assets.Asset, limit int64, fn func(h *Holder) error) error {
+	q := goldb.NewQuery(dbIdxRichList, asset).OrderDesc()
+	if limit > 0 {
+		q.Limit(limit)
+	}
+	return s.db.Fetch(q, func(rec goldb.Record) error {
+		var h Holder
+		var balance []byte
+		rec.MustDecodeKey(&asset, &balance, &h.Address)
+		h.Balance.SetBytes(balance)
+		return fn(&h)
+	})
+}
+
+func (s *BlockchainStorage) RichList(asset assets.Asset, limit int64) (holders []*Holder, err error) {
+	err = s.FetchRichList(asset, limit, func(h *Holder) error {
+		holders = append(holders, h)
+		return nil
+	})
+	return
+}
+
+// AssetHolders returns count of holders, top-N holders and distribution of holders by balance
+func (s *BlockchainStorage) AssetHolders(asset assets.Asset, top int64) (inf *AssetHolders, err error) {
+	inf = &AssetHolders{Asset: asset}
+	if inf.Top, err = s.RichList(asset, top); err != nil {
+		return
+	}
+	err = s.db.Fetch(goldb.NewQuery(dbIdxHolders, asset), func(rec goldb.Record) error {
+		var bucket int
+		var count int64
+		rec.MustDecodeKey(&asset, &bucket)
+		rec.MustDecode(&count)
+		if count > 0 {
+			min := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(bucket)), nil)
+			max := new(big.Int).Mul(min, big.NewInt(10))
+			b := &HoldersBucket{Count: count}
+			b.Min.SetBytes(min.Bytes())
+			b.Max.SetBytes(max.Bytes())
+			inf.Buckets = append(inf.Buckets, b)
+			inf.Holders += count
+		}
+		return nil
+	})
+	return
+}
+
+// migrateRichList builds rich-list index and holders buckets by actual balances
+func migrateRichList(s *BlockchainStorage, _ int) error {
+	for _, q := range []*goldb.Query{
+		goldb.NewQuery(dbIdxRichList),
+		goldb.NewQuery(dbIdxHolders),
+	} {
+		if err := s.clearTable(q); err != nil {
+			return err
+		}
+	}
+	q := goldb.NewQuery(dbIdxBalances).Limit(migrationBatchSize)
+	for {
+		var balances []*balanceRecord
+		err := s.db.Fetch(q, func(rec goldb.Record) error {
+			b := &balanceRecord{}
+			rec.MustDecodeKey(&b.asset, &b.addr)
+			rec.MustDecode(&b.balance)
+			balances = append(balances, b)
+			return nil
+		})
+		if err != nil || len(balances) == 0 {
+			return err
+		}
+		err = s.db.Exec(func(tr *goldb.Transaction) {
+			for _, b := range balances {
+				updateRichList(tr, b.asset, b.addr, bignum.Int{}, b.balance)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		last := balances[len(balances)-1]
+		q.Offset(last.asset, last.addr)
+	}
+}
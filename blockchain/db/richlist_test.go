@@ -0,0 +1,37 @@
+package db
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRichListBalance_order(t *testing.T) {
+	a, _ := richListBalance(bignum.NewInt(255))
+	b, _ := richListBalance(bignum.NewInt(256))
+	c, _ := richListBalance(bignum.NewInt(1e18))
+
+	assert.Equal(t, richListBalanceSize, len(a))
+	assert.Equal(t, richListBalanceSize, len(c))
+	assert.True(t, bytes.Compare(a, b) < 0)
+	assert.True(t, bytes.Compare(b, c) < 0)
+}
+
+func TestRichListBalance_failByLength(t *testing.T) {
+	var balance bignum.Int
+	balance.SetBytes(append([]byte{1}, make([]byte, richListBalanceSize)...)) // 2^256
+
+	_, err := richListBalance(balance)
+
+	assert.Equal(t, errRichListBalanceTooBig, err)
+}
+
+func TestHoldersBucket(t *testing.T) {
+	assert.Equal(t, 0, holdersBucket(bignum.NewInt(1)))
+	assert.Equal(t, 0, holdersBucket(bignum.NewInt(9)))
+	assert.Equal(t, 1, holdersBucket(bignum.NewInt(10)))
+	assert.Equal(t, 9, holdersBucket(bignum.NewInt(1e9)))
+	assert.Equal(t, 9, holdersBucket(bignum.NewInt(1e10-1)))
+}
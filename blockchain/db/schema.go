@@ -0,0 +1,132 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/log"
+)
+
+// baseSchemaVersion is the version of db-layout without stored schema version
+const baseSchemaVersion = 1
+
+const (
+	// dbTabSchema keys
+	schemaKeyVersion  = 0 // () => schemaVersion
+	schemaKeyProgress = 1 // (ver) => last migrated blockNum
+	schemaKeySnapshot = 2 // () => blockNum of snapshot (node has been restored from snapshot)
+	schemaKeyReindex  = 3 // () => last reindexed blockNum (reindex has not been finished)
+)
+
+// migrationBatchSize is the count of blocks processed in one db-transaction by block-migrations
+const migrationBatchSize = 1000
+
+type migration struct {
+	ver  int                                       // schema version after migration
+	name string                                    //
+	fn   func(s *BlockchainStorage, ver int) error // is called with ver of the migration
+}
+
+// migrations is the registry of db-schema migrations.
+// Each migration upgrades schema from version (ver-1) to ver; versions have to be consecutive.
+var migrations = []migration{
+	{2, "rich-list index", migrateRichList},
+	{3, "asset feed index", migrateAssetFeed},
+	{4, "address sources index", migrateAddrSources},
+	{5, "referral rewards totals", migrateReferralRewards},
+	{6, "users list and nick prefixes index", migrateUserIndexes},
+	{7, "transfer counterparties index", migrateCounterparties},
+	{8, "supply statistic by balances", migrateSupplyStat},
+}
+
+var errUnsupportedSchemaVer = errors.New("db: unsupported schema version")
+
+// SchemaVersion returns the version of db-schema supported by the node
+func SchemaVersion() int {
+	return baseSchemaVersion + len(migrations)
+}
+
+func (s *BlockchainStorage) schemaVersion() (ver int, err error) {
+	ok, err := s.db.GetVar(goldb.Key(dbTabSchema, schemaKeyVersion), &ver)
+	if err == nil && !ok {
+		ver = baseSchemaVersion
+	}
+	return
+}
+
+func (s *BlockchainStorage) setSchemaVersion(ver int) error {
+	return s.db.Exec(func(tr *goldb.Transaction) {
+		tr.PutVar(goldb.Key(dbTabSchema, schemaKeyVersion), ver)
+		tr.Delete(goldb.Key(dbTabSchema, schemaKeyProgress, ver))
+	})
+}
+
+// migrate upgrades db-schema to actual version. An interrupted migration continues from the last saved point.
+func (s *BlockchainStorage) migrate() error {
+	ver, err := s.schemaVersion()
+	if err != nil {
+		return err
+	}
+	if ver > SchemaVersion() {
+		return fmt.Errorf("%v %d (supported version %d)", errUnsupportedSchemaVer, ver, SchemaVersion())
+	}
+	if ver == SchemaVersion() {
+		return nil
+	}
+	if s.lastBlock.Num == 0 { // new db
+		return s.setSchemaVersion(SchemaVersion())
+	}
+	if _, ok, err := s.ReindexProgress(); err != nil || ok { // indexes will be built by actual schema on reindex
+		return err
+	}
+	for _, m := range migrations {
+		if m.ver <= ver {
+			continue
+		}
+		log.Printf("db> migration #%d %q started", m.ver, m.name)
+		if err := m.fn(s, m.ver); err != nil {
+			return fmt.Errorf("db: migration #%d %q failed: %v", m.ver, m.name, err)
+		}
+		if err := s.setSchemaVersion(m.ver); err != nil {
+			return err
+		}
+		log.Printf("db> migration #%d %q finished", m.ver, m.name)
+	}
+	return nil
+}
+
+// migrateBlocks calls fn for each stored block in batches of db-transactions.
+// Progress is saved with each batch, so the restarted migration continues from the last migrated block.
+func (s *BlockchainStorage) migrateBlocks(ver int, fn func(tr *goldb.Transaction, block *blockchain.Block)) error {
+	var lastNum uint64
+	if _, err := s.db.GetVar(goldb.Key(dbTabSchema, schemaKeyProgress, ver), &lastNum); err != nil {
+		return err
+	}
+	for maxNum := s.lastBlock.Num; lastNum < maxNum; {
+		var blocks []*blockchain.Block
+		err := s.FetchBlocks(lastNum, migrationBatchSize, false, func(block *blockchain.Block) error {
+			blocks = append(blocks, block)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(blocks) == 0 {
+			break
+		}
+		err = s.db.Exec(func(tr *goldb.Transaction) {
+			for _, block := range blocks {
+				fn(tr, block)
+			}
+			tr.PutVar(goldb.Key(dbTabSchema, schemaKeyProgress, ver), blocks[len(blocks)-1].Num)
+		})
+		if err != nil {
+			return err
+		}
+		lastNum = blocks[len(blocks)-1].Num
+		log.Printf("db> migration #%d: block %d of %d (%.1f%%)", ver, lastNum, maxNum, float64(lastNum)*100/float64(maxNum))
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations_consecutiveVersions(t *testing.T) {
+	for i, m := range migrations {
+		assert.Equal(t, baseSchemaVersion+i+1, m.ver, m.name)
+		assert.NotNil(t, m.fn, m.name)
+	}
+	assert.Equal(t, baseSchemaVersion+len(migrations), SchemaVersion())
+}
@@ -0,0 +1,534 @@
+package db
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/denisskin/bin"
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/commons/log"
+	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/crypto/merkle"
+	"github.com/likecoin-pro/likecoin/crypto/patricia"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+const (
+	snapshotVersion   = 3
+	snapshotBatchSize = 10000 // records in one db-transaction
+)
+
+var (
+	errSnapshotInvalidVersion = errors.New("snapshot: unsupported snapshot version")
+	errSnapshotNotEmptyDB     = errors.New("snapshot: db is not empty")
+	errSnapshotInvalidRecord  = errors.New("snapshot: invalid record")
+	errSnapshotInvalidStat    = errors.New("snapshot: statistic does not match block header or state")
+	errSnapshotInvalidNames   = errors.New("snapshot: owners of names do not match state")
+)
+
+// ErrIncompleteIndex is returned by queries of indexes which are not restored from snapshot (see CheckFullIndexes)
+var ErrIncompleteIndex = errors.New("index is incomplete (node has been restored from snapshot)")
+
+// snapshotTables returns tables which records are copied to snapshot as is.
+// Trees are verified by roots of block header; index of txIDs can not be verified without txs and is verified by count of txs of statistic only.
+func snapshotTables() [][]byte {
+	return [][]byte{
+		goldb.Key(dbTabStateTree),
+		goldb.Key(dbTabChainTree),
+		goldb.Key(dbIdxTxID),
+	}
+}
+
+/*
+WriteSnapshot writes the state of blockchain at the last block.
+
+Snapshot format:
+
+	snapshotVersion, schemaVersion, BlockHeader, Statistic
+	records: (key, value)...  ends with an empty key
+	count of headers, BlockHeader...
+	count of txs, (txUID, Transaction, merkleProof)...
+
+Records are state-tree and chain-tree nodes and index of txIDs (registered txs are rejected after restore).
+Txs are registrations of users and changes of name assets; headers are headers of blocks of the txs.
+Indexes of users and names are rebuilt from the txs on restore.
+
+Indexes of media-sources, totals of referral rewards and transfers by counterparties are not included in snapshot
+(see CheckFullIndexes); history of addresses starts from the snapshot block.
+*/
+func (s *BlockchainStorage) WriteSnapshot(w io.Writer) (err error) {
+	s.mxW.Lock()
+	defer s.mxW.Unlock()
+
+	bw := bin.NewWriter(w)
+	bw.WriteVar(snapshotVersion)
+	bw.WriteVar(SchemaVersion())
+	bw.WriteVar(s.lastBlock.BlockHeader)
+	bw.WriteVar(s.stat)
+
+	var n int64
+	writeRecord := func(key, value []byte) error {
+		if n++; n%1e6 == 0 {
+			log.Printf("snapshot> %d records", n)
+		}
+		bw.WriteBytes(key)
+		return bw.WriteBytes(value)
+	}
+
+	// state, chain, txIDs
+	for _, q := range []*goldb.Query{
+		goldb.NewQuery(dbTabStateTree),
+		goldb.NewQuery(dbTabChainTree),
+		goldb.NewQuery(dbIdxTxID),
+	} {
+		err = s.db.Fetch(q, func(rec goldb.Record) error {
+			return writeRecord(rec.Key, rec.Value)
+		})
+		if err != nil {
+			return
+		}
+	}
+	// end of records
+	if err = bw.WriteBytes(nil); err != nil {
+		return
+	}
+
+	// headers of blocks with txs of users and names
+	txUIDs, err := s.snapshotTxUIDs()
+	if err != nil {
+		return
+	}
+	var blockNums []uint64
+	for _, txUID := range txUIDs {
+		if blockNum, _ := decodeTxUID(txUID); len(blockNums) == 0 || blockNums[len(blockNums)-1] != blockNum {
+			blockNums = append(blockNums, blockNum)
+		}
+	}
+	bw.WriteVar(len(blockNums))
+	for _, blockNum := range blockNums {
+		h, err := s.BlockHeader(blockNum)
+		if err != nil {
+			return err
+		}
+		if err = bw.WriteVar(h); err != nil {
+			return err
+		}
+	}
+
+	// txs with merkle proofs by TxRoot of block header
+	bw.WriteVar(len(txUIDs))
+	var txs []*blockchain.Transaction
+	var hashes [][]byte
+	for _, txUID := range txUIDs {
+		blockNum, txIdx := decodeTxUID(txUID)
+		if len(txs) == 0 || txs[0].BlockNum() != blockNum {
+			if txs, err = s.BlockTxs(blockNum); err != nil {
+				return
+			}
+			hashes = make([][]byte, len(txs))
+			for i, tx := range txs {
+				hashes[i] = tx.TxStHash()
+			}
+		}
+		if txIdx >= len(txs) {
+			return errTxNotFound
+		}
+		proof, _ := merkle.Proof(append([][]byte{}, hashes...), txIdx)
+		bw.WriteVar(txUID)
+		bw.WriteVar(txs[txIdx])
+		if err = bw.WriteBytes(proof); err != nil {
+			return
+		}
+	}
+
+	log.Printf("snapshot> block#%d: %d records, %d txs", s.lastBlock.Num, n, len(txUIDs))
+	return
+}
+
+// snapshotTxUIDs returns ordered txUIDs of user registrations and of changes of name assets
+func (s *BlockchainStorage) snapshotTxUIDs() (txUIDs []uint64, err error) {
+	uids := map[uint64]bool{}
+	err = s.db.Fetch(goldb.NewQuery(dbIdxUsers), func(rec goldb.Record) error {
+		var txUID uint64
+		rec.MustDecode(&txUID)
+		uids[txUID] = true
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	err = s.db.Fetch(goldb.NewQuery(dbIdxAsset), func(rec goldb.Record) error {
+		var asset assets.Asset
+		var txUID uint64
+		rec.MustDecodeKey(&asset, &txUID)
+		if asset.IsName() {
+			uids[txUID] = true
+		}
+		return nil
+	})
+	for txUID := range uids {
+		txUIDs = append(txUIDs, txUID)
+	}
+	sort.Slice(txUIDs, func(i, j int) bool { return txUIDs[i] < txUIDs[j] })
+	return
+}
+
+// LoadSnapshot restores the state of blockchain from snapshot to empty db.
+// State-tree and chain-tree are verified by roots of the signed block header,
+// headers of previous blocks are verified by the chain-tree, txs are verified by merkle proofs of TxRoot of their headers.
+// Balances are restored from the verified state-tree; indexes of users and names are rebuilt from the verified txs.
+func (s *BlockchainStorage) LoadSnapshot(r io.Reader) (err error) {
+	s.mxW.Lock()
+	defer s.mxW.Unlock()
+
+	if s.lastBlock.Num != 0 {
+		return errSnapshotNotEmptyDB
+	}
+
+	var (
+		br        = bin.NewReader(r)
+		ver       int
+		schemaVer int
+		h         *blockchain.BlockHeader
+		stat      *Statistic
+	)
+	br.ReadVar(&ver)
+	br.ReadVar(&schemaVer)
+	br.ReadVar(&h)
+	if err = br.ReadVar(&stat); err != nil {
+		return
+	}
+	if ver != snapshotVersion || schemaVer != SchemaVersion() {
+		return errSnapshotInvalidVersion
+	}
+	if err = h.VerifyHeader(nil, s.Cfg); err != nil {
+		return
+	}
+	if stat.Blocks != h.Num {
+		return errSnapshotInvalidStat
+	}
+
+	defer func() {
+		if err != nil { // rollback
+			for _, q := range append(derivedTables(), goldb.NewQuery(dbTabHeaders), goldb.NewQuery(dbTabTxs)) {
+				s.clearTable(q)
+			}
+		}
+	}()
+
+	// copy records
+	var keys, values [][]byte
+	var txIDs int64 // count of records of txIDs index
+	maxTxUID := encodeTxUID(h.Num+1, 0)
+	putRecords := func() error {
+		err := s.db.Exec(func(tr *goldb.Transaction) {
+			for i, key := range keys {
+				tr.Put(key, values[i])
+			}
+		})
+		keys, values = keys[:0], values[:0]
+		return err
+	}
+	for n := 1; ; n++ {
+		key, err := br.ReadBytes()
+		if err != nil {
+			return err
+		}
+		if len(key) == 0 {
+			break
+		}
+		if !isSnapshotKey(key) {
+			return errSnapshotInvalidRecord
+		}
+		value, err := br.ReadBytes()
+		if err != nil {
+			return err
+		}
+		if bytes.HasPrefix(key, goldb.Key(dbIdxTxID)) {
+			var txUID uint64
+			if (goldb.Record{Key: key, Value: value}).Decode(&txUID) != nil || txUID == 0 || txUID >= maxTxUID {
+				return errSnapshotInvalidRecord
+			}
+			txIDs++
+		}
+		keys, values = append(keys, key), append(values, value)
+		if len(keys) >= snapshotBatchSize {
+			if err = putRecords(); err != nil {
+				return err
+			}
+		}
+		if n%1e6 == 0 {
+			log.Printf("snapshot> %d records", n)
+		}
+	}
+	if err = putRecords(); err != nil {
+		return
+	}
+	if txIDs != stat.Txs { // each tx of chain is registered in txIDs index
+		return errSnapshotInvalidStat
+	}
+
+	// verify chain-tree
+	chainTree := patricia.NewSubTree(s.db, goldb.Key(dbTabChainTree))
+	if err = chainTree.Fetch(func(_, _ []byte) error { return nil }); err != nil {
+		return
+	}
+	var blockHash []byte
+	if err = chainTree.GetVar(h.Num, &blockHash); err != nil {
+		return
+	}
+	if chainRoot, _ := chainTree.Root(); !bytes.Equal(chainRoot, h.ChainRoot) || !bytes.Equal(blockHash, h.Hash()) {
+		return errIncorrectChainRoot
+	}
+
+	// verify state-tree, restore balances
+	stateTree := patricia.NewSubTree(s.db, goldb.Key(dbTabStateTree))
+	var balances []*balanceRecord
+	putBalances := func() error {
+		err := s.db.Exec(func(tr *goldb.Transaction) {
+			for _, b := range balances {
+				tr.PutVar(goldb.Key(dbIdxBalances, b.asset, b.addr), b.balance)
+				updateRichList(tr, b.asset, b.addr, bignum.Int{}, b.balance)
+			}
+		})
+		balances = balances[:0]
+		return err
+	}
+	nameOwners := map[string]crypto.Address{} // name-asset => address by state
+	err = stateTree.Fetch(func(key, value []byte) error {
+		if len(key) <= crypto.AddressLength {
+			return errSnapshotInvalidRecord
+		}
+		b := &balanceRecord{asset: assets.Asset(key[crypto.AddressLength:])}
+		copy(b.addr[:], key)
+		if b.balance.SetBytes(value); b.balance.IsZero() {
+			return nil
+		}
+		if b.asset.IsName() {
+			nameOwners[string(b.asset)] = b.addr
+		}
+		if balances = append(balances, b); len(balances) >= snapshotBatchSize {
+			return putBalances()
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	if err = putBalances(); err != nil {
+		return
+	}
+	if stateRoot, _ := stateTree.Root(); !bytes.Equal(stateRoot, h.StateRoot) {
+		return errIncorrectStateRoot
+	}
+
+	// supply of coins by statistic has to be equal to the sum of the verified balances
+	supply, err := coinsSupply(s.db)
+	if err != nil {
+		return
+	}
+	if !stat.verifySupply(supply) {
+		return errSnapshotInvalidStat
+	}
+
+	// verify authority of block signer by validator set of the verified state
+	if s.Cfg.Consensus == blockchain.ConsensusPoA {
+		validators, err := fetchValidators(s.db)
+		if err != nil {
+			return err
+		}
+		if err = verifySnapshotMiner(h, validators); err != nil {
+			return err
+		}
+	}
+
+	// headers of previous blocks, verified by chain-tree
+	var nHeaders int
+	if err = br.ReadVar(&nHeaders); err != nil {
+		return
+	}
+	headers := map[uint64]*blockchain.BlockHeader{}
+	for i := 0; i < nHeaders; i++ {
+		var bh *blockchain.BlockHeader
+		if err = br.ReadVar(&bh); err != nil {
+			return
+		}
+		if bh == nil || bh.Num == 0 || bh.Num >= h.Num {
+			return errSnapshotInvalidRecord
+		}
+		var hash []byte
+		if err = chainTree.GetVar(bh.Num, &hash); err != nil {
+			return
+		}
+		if !bytes.Equal(hash, bh.Hash()) {
+			return errSnapshotInvalidRecord
+		}
+		headers[bh.Num] = bh
+		keys, values = append(keys, goldb.Key(dbTabHeaders, bh.Num)), append(values, bin.Encode(bh))
+		if len(keys) >= snapshotBatchSize {
+			if err = putRecords(); err != nil {
+				return
+			}
+		}
+	}
+	if err = putRecords(); err != nil {
+		return
+	}
+
+	// txs of users and names, verified by merkle proofs; indexes are rebuilt from the txs
+	var nTxs int
+	if err = br.ReadVar(&nTxs); err != nil {
+		return
+	}
+	names := map[string]crypto.Address{} // name-asset => address by txs (txs are ordered by txUID)
+	var users int64
+	var txs []*snapshotTx
+	putTxs := func() error {
+		err := s.db.Exec(func(tr *goldb.Transaction) {
+			for _, t := range txs {
+				s.putSnapshotTx(tr, t.txUID, t.tx, names)
+			}
+		})
+		txs = txs[:0]
+		return err
+	}
+	for i := 0; i < nTxs; i++ {
+		t := &snapshotTx{}
+		br.ReadVar(&t.txUID)
+		br.ReadVar(&t.tx)
+		proof, err := br.ReadBytes()
+		if err != nil {
+			return err
+		}
+		blockNum, _ := decodeTxUID(t.txUID)
+		bh := headers[blockNum]
+		if t.tx == nil || bh == nil || t.tx.ChainID != h.ChainID || !merkle.Verify(t.tx.TxStHash(), proof, bh.TxRoot) {
+			return errSnapshotInvalidRecord
+		}
+		if t.tx.Type == object.TxTypeUser {
+			users++
+		}
+		if txs = append(txs, t); len(txs) >= snapshotBatchSize {
+			if err = putTxs(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = putTxs(); err != nil {
+		return
+	}
+	if users != stat.Users { // registrations of all users are in snapshot
+		return errSnapshotInvalidStat
+	}
+
+	// owners of names by rebuilt index have to match the verified state
+	if len(names) != len(nameOwners) {
+		return errSnapshotInvalidNames
+	}
+	for asset, addr := range nameOwners {
+		if owner, ok := names[asset]; !ok || owner != addr {
+			return errSnapshotInvalidNames
+		}
+	}
+
+	// put block header, statistic, schema version
+	err = s.db.Exec(func(tr *goldb.Transaction) {
+		tr.PutVar(goldb.Key(dbTabHeaders, h.Num), h)
+		tr.PutVar(goldb.Key(dbTabStat, h.Timestamp, h.Num), stat)
+		tr.PutVar(goldb.Key(dbTabSchema, schemaKeyVersion), schemaVer)
+		tr.PutVar(goldb.Key(dbTabSchema, schemaKeySnapshot), h.Num)
+	})
+	if err != nil {
+		return
+	}
+
+	s.mxR.Lock()
+	s.lastBlock = blockchain.NewBlock(h, nil)
+	s.stat = stat
+	s.mxR.Unlock()
+
+	log.Printf("snapshot> ✅ state of block#%d is restored", h.Num)
+	return
+}
+
+// CheckFullIndexes returns ErrIncompleteIndex if node has been restored from snapshot.
+// Indexes of media-sources, totals of referral rewards and transfers by counterparties are built by blocks since genesis;
+// they are not restored from snapshot, so queries of these indexes are refused on restored node.
+func (s *BlockchainStorage) CheckFullIndexes() error {
+	if restored, err := isRestoredFromSnapshot(s.db); err != nil {
+		return err
+	} else if restored {
+		return ErrIncompleteIndex
+	}
+	return nil
+}
+
+// putSnapshotTx puts verified tx of snapshot and rebuilds its indexes (users, invites, names) by opened db-transaction
+func (s *BlockchainStorage) putSnapshotTx(tr *goldb.Transaction, txUID uint64, tx *blockchain.Transaction, names map[string]crypto.Address) {
+	blockNum, txIdx := decodeTxUID(txUID)
+	tr.PutVar(goldb.Key(dbTabTxs, blockNum, txIdx), tx)
+	tr.PutID(goldb.Key(dbIdxTxID, tx.ID()), txUID)
+
+	if tx.Type == object.TxTypeUser {
+		userID := tx.Sender.ID()
+		tr.PutID(goldb.Key(dbIdxUsers, userID), txUID)
+		if usr, ok := tx.TxObject().(*object.User); ok && usr != nil {
+			if usr.ReferrerID != 0 {
+				tr.PutID(goldb.Key(dbIdxInvites, usr.ReferrerID, txUID), txUID)
+			}
+			putUserIndexes(tr, txUID, userID, usr)
+		}
+	}
+	for stIdx, v := range tx.StateUpdates {
+		if v.ChainID == s.Cfg.ChainID && v.Asset.IsName() {
+			tr.PutVar(goldb.Key(dbIdxAsset, v.Asset, txUID, stIdx, v.Address), v.Balance)
+			if v.Balance.Sign() > 0 {
+				names[string(v.Asset)] = v.Address
+			} else if owner, ok := names[string(v.Asset)]; ok && owner == v.Address {
+				delete(names, string(v.Asset))
+			}
+		}
+	}
+}
+
+// verifySnapshotMiner verifies that snapshot block is signed by a validator of the snapshot state (or by master key if validator set is empty)
+func verifySnapshotMiner(h *blockchain.BlockHeader, validators []crypto.Address) error {
+	if len(validators) == 0 {
+		if !h.Miner.Equal(config.MasterPublicKey) {
+			return blockchain.ErrInvalidMinerKey
+		}
+		return nil
+	}
+	for _, addr := range validators {
+		if addr == h.Miner.Address() {
+			return nil
+		}
+	}
+	return blockchain.ErrInvalidMinerKey
+}
+
+type snapshotTx struct {
+	txUID uint64
+	tx    *blockchain.Transaction
+}
+
+type balanceRecord struct {
+	asset   assets.Asset
+	addr    crypto.Address
+	balance bignum.Int
+}
+
+func isSnapshotKey(key []byte) bool {
+	for _, prefix := range snapshotTables() {
+		if bytes.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+type SourceInfo struct {
+	SourceID string                  `json:"source"`  // media-source ID
+	Asset    assets.Asset            `json:"asset"`   //
+	Likes    int64                   `json:"likes"`   // current count likes for media-source
+	Address  crypto.Address          `json:"address"` // current address associated with the media-source
+	LastTx   *blockchain.Transaction `json:"last_tx"` // last emission tx by media-source
+	Supply   []*SourceSupply         `json:"supply"`  // total supply by addresses
+}
+
+type SourceSupply struct {
+	SourceID string         `json:"source,omitempty"` //
+	Address  crypto.Address `json:"address"`          //
+	Total    bignum.Int     `json:"total"`            // total emission by media-source to address
+}
+
+// SourceInfo returns current state of media-source and total supply by addresses
+func (s *BlockchainStorage) SourceInfo(asset assets.Asset, sourceID string) (inf *SourceInfo, err error) {
+	if err = s.CheckFullIndexes(); err != nil {
+		return
+	}
+	tx, out, err := s.lastSourceTx(asset, sourceID)
+	if err != nil {
+		return
+	}
+	if tx == nil {
+		return nil, ErrSourceNotFound
+	}
+	inf = &SourceInfo{
+		SourceID: sourceID,
+		Asset:    asset,
+		LastTx:   tx,
+	}
+	if out != nil {
+		inf.Likes = out.SourceValue
+		inf.Address = out.Address
+	}
+	err = s.db.Fetch(goldb.NewQuery(dbIdxSourceAddr, asset, sourceID), func(rec goldb.Record) error {
+		v := &SourceSupply{}
+		rec.MustDecodeKey(&asset, &sourceID, &v.Address)
+		rec.MustDecode(&v.Total)
+		inf.Supply = append(inf.Supply, v)
+		return nil
+	})
+	return
+}
+
+// FetchSourceTxs fetches emission transactions by media-source
+func (s *BlockchainStorage) FetchSourceTxs(
+	asset assets.Asset,
+	sourceID string,
+	offset uint64,
+	limit int64,
+	orderDesc bool,
+	fn func(tx *blockchain.Transaction) error,
+) error {
+	if err := s.CheckFullIndexes(); err != nil {
+		return err
+	}
+	q := goldb.NewQuery(dbIdxSourceTx, asset, sourceID)
+	if offset > 0 {
+		q.Offset(offset)
+	}
+	if limit > 0 {
+		q.Limit(limit)
+	}
+	q.Order(orderDesc)
+	return s.db.Fetch(q, func(rec goldb.Record) error {
+		var txUID uint64
+		rec.MustDecodeKey(&asset, &sourceID, &txUID)
+		tx, err := s.transactionByUID(txUID)
+		if err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// FetchAddressSources fetches media-sources which paid to the address, ordered by sourceID
+func (s *BlockchainStorage) FetchAddressSources(
+	asset assets.Asset,
+	addr crypto.Address,
+	offset string,
+	limit int64,
+	fn func(v *SourceSupply) error,
+) error {
+	if err := s.CheckFullIndexes(); err != nil {
+		return err
+	}
+	q := goldb.NewQuery(dbIdxAddrSources, asset, addr)
+	if offset != "" {
+		q.Offset(offset)
+	}
+	if limit > 0 {
+		q.Limit(limit)
+	}
+	return s.db.Fetch(q, func(rec goldb.Record) error {
+		v := &SourceSupply{}
+		rec.MustDecodeKey(&asset, &addr, &v.SourceID)
+		v.Address = addr
+		total, err := s.SourceTotalSupply(asset, addr, v.SourceID)
+		if err != nil {
+			return err
+		}
+		v.Total = total
+		return fn(v)
+	})
+}
+
+// migrateAddrSources builds index (asset, addr, sourceID) by primary emission transactions
+func migrateAddrSources(s *BlockchainStorage, ver int) error {
+	return s.migrateBlocks(ver, func(tr *goldb.Transaction, block *blockchain.Block) {
+		for _, tx := range block.Txs {
+			if emission, ok := tx.TxObject().(*object.Emission); ok && emission.IsPrimaryEmission() {
+				for _, out := range emission.Outs {
+					if out.Delta > 0 {
+						tr.Put(goldb.Key(dbIdxAddrSources, emission.Asset, out.Address, out.SourceID), nil)
+					}
+				}
+			}
+		}
+	})
+}
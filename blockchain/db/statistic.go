@@ -1,7 +1,10 @@
 package db
 
 import (
+	"errors"
+
 	"github.com/denisskin/bin"
+	"github.com/denisskin/goldb"
 	"github.com/likecoin-pro/likecoin/assets"
 	"github.com/likecoin-pro/likecoin/commons/bignum"
 	"github.com/likecoin-pro/likecoin/commons/enc"
@@ -15,6 +18,17 @@ type Statistic struct {
 	Coins  []CoinStatistic `json:"coins"`  //
 }
 
+// StatPoint is the statistic at the end of time interval
+type StatPoint struct {
+	Timestamp int64 `json:"timestamp"` // end of interval in µsec
+	*Statistic
+}
+
+// maxStatSeriesPoints is the max count of intervals in statistic series
+const maxStatSeriesPoints = 10000
+
+var errStatSeriesTooLong = errors.New("stat: too many intervals in series")
+
 type CoinStatistic struct {
 	Asset  assets.Asset `json:"asset"`  //
 	Likes  int64        `json:"likes"`  //
@@ -100,16 +114,84 @@ func (s *Statistic) setCoinStat(v CoinStatistic) {
 	s.Coins = append(s.Coins, v)
 }
 
-func (s *Statistic) IncSupplyStat(emission *object.Emission) {
+// IncEmissionStat refreshes likes and rate by primary emission
+func (s *Statistic) IncEmissionStat(emission *object.Emission) {
+	if !emission.IsPrimaryEmission() {
+		return
+	}
 	c := s.CoinStat(emission.Asset)
-	if emission.IsPrimaryEmission() {
-		c.Rate = emission.Rate
-		c.Likes += emission.TotalDelta()
+	c.Rate = emission.Rate
+	c.Likes += emission.TotalDelta()
+	s.setCoinStat(c)
+}
+
+// IncSupplyStat refreshes supply of coin by change of balance of address.
+// Supply is the sum of balances of the chain (emissions and claims increase it, transfers to other chains decrease it)
+func (s *Statistic) IncSupplyStat(asset assets.Asset, oldBalance, newBalance bignum.Int) {
+	if !asset.IsCoin() || oldBalance.Equal(newBalance) {
+		return
 	}
-	c.Supply = c.Supply.Add(emission.TotalAmount())
+	c := s.CoinStat(asset)
+	c.Supply = c.Supply.Add(newBalance).Sub(oldBalance)
 	s.setCoinStat(c)
 }
 
+// coinsSupply returns the sums of balances of coins by db-context (asset => supply)
+func coinsSupply(c dbContext) (supply map[string]bignum.Int, err error) {
+	supply = map[string]bignum.Int{}
+	err = c.Fetch(goldb.NewQuery(dbIdxBalances), func(rec goldb.Record) error {
+		var asset assets.Asset
+		var balance bignum.Int
+		rec.MustDecodeKey(&asset)
+		rec.MustDecode(&balance)
+		if asset.IsCoin() {
+			supply[string(asset)] = supply[string(asset)].Add(balance)
+		}
+		return nil
+	})
+	return
+}
+
+// verifySupply returns true if supply of each coin by statistic is equal to the sum of balances
+func (s *Statistic) verifySupply(supply map[string]bignum.Int) bool {
+	for _, c := range s.Coins {
+		if !c.Supply.Equal(supply[string(c.Asset)]) {
+			return false
+		}
+	}
+	for asset, v := range supply {
+		if !s.CoinStat(assets.Asset(asset)).Supply.Equal(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// migrateSupplyStat recounts supply of the actual statistic by balances (previously supply was counted by emissions only)
+func migrateSupplyStat(s *BlockchainStorage, _ int) error {
+	supply, err := coinsSupply(s.db)
+	if err != nil {
+		return err
+	}
+	stat := s.stat.Clone()
+	for i, c := range stat.Coins {
+		stat.Coins[i].Supply = supply[string(c.Asset)]
+	}
+	for asset, v := range supply {
+		c := stat.CoinStat(assets.Asset(asset))
+		c.Supply = v
+		stat.setCoinStat(c)
+	}
+	h := s.lastBlock.BlockHeader
+	if err = s.db.Exec(func(tr *goldb.Transaction) {
+		tr.PutVar(goldb.Key(dbTabStat, h.Timestamp, h.Num), stat)
+	}); err != nil {
+		return err
+	}
+	s.stat = stat
+	return nil
+}
+
 func (s *Statistic) IncVolumeStat(tr *object.Transfer) {
 	for _, out := range tr.Outs {
 		if out.Asset.IsCoin() {
@@ -39,3 +39,24 @@ func TestStatistic_Decode(t *testing.T) {
 	  ]
 	}`, enc.JSON(v))
 }
+
+func TestStatistic_IncSupplyStat(t *testing.T) {
+	stat := &Statistic{}
+
+	stat.IncSupplyStat(assets.Default, bignum.Int{}, bignum.NewInt(100))
+	stat.IncSupplyStat(assets.Default, bignum.NewInt(100), bignum.NewInt(70)) // transfer to another chain
+	stat.IncSupplyStat(assets.NewName("alice"), bignum.Int{}, bignum.NewInt(1))
+
+	assert.Equal(t, 1, len(stat.Coins))
+	assert.Equal(t, int64(70), stat.CoinStat(assets.Default).Supply.Int64())
+}
+
+func TestStatistic_verifySupply(t *testing.T) {
+	stat := &Statistic{}
+	stat.IncSupplyStat(assets.Default, bignum.Int{}, bignum.NewInt(100))
+
+	assert.True(t, stat.verifySupply(map[string]bignum.Int{string(assets.Default): bignum.NewInt(100)}))
+	assert.False(t, stat.verifySupply(map[string]bignum.Int{string(assets.Default): bignum.NewInt(99)}))
+	assert.False(t, stat.verifySupply(map[string]bignum.Int{}))
+	assert.False(t, stat.verifySupply(map[string]bignum.Int{string(assets.Default): bignum.NewInt(100), "\x00\x02": bignum.NewInt(1)}))
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/likecoin-pro/likecoin/blockchain"
 	"github.com/likecoin-pro/likecoin/blockchain/state"
 	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/config"
 	"github.com/likecoin-pro/likecoin/crypto"
 	"github.com/likecoin-pro/likecoin/crypto/patricia"
 	"github.com/likecoin-pro/likecoin/object"
@@ -29,15 +30,21 @@ type BlockchainStorage struct {
 	mxW          sync.Mutex
 	mxR          sync.RWMutex
 	lastBlock    *blockchain.Block //
+	newBlocks    chan struct{}     // is closed on commit of new blocks
 	stat         *Statistic        //
 	cacheHeaders *gosync.Cache     // blockNum => *BlockHeader
 	cacheTxs     *gosync.Cache     // blockNum => []*Transaction
 	cacheIdxTx   *gosync.Cache     // idxKey => *Transaction
 	middleware   []Middleware      //
+	onCommit     []CommitHandler   //
+	syncInfo     func() *SyncInfo  // progress of replication
 }
 
 type Middleware func(*goldb.Transaction, *blockchain.Block)
 
+// CommitHandler is called after blocks have been committed to db
+type CommitHandler func(blocks []*blockchain.Block)
+
 const (
 	// tables
 	dbTabHeaders   = 0x01 // (blockNum) => BlockHeader
@@ -45,18 +52,28 @@ const (
 	dbTabChainTree = 0x03 //
 	dbTabStateTree = 0x04 // (asset, addr) => sateValue
 	dbTabStat      = 0x05 // (ts) => Statistic
+	dbTabSchema    = 0x06 // (key) => schema version, migration progress
+	dbTabGenesis   = 0x07 // () => genesis json
 
 	// indexes
 	dbIdxTxID          = 0x20 // (txID)                        => txNum
-	dbIdxAsset         = 0x21 // (asset, txNum)                => sateValue
+	dbIdxAsset         = 0x21 // (asset, txNum, stIdx, addr)   => sateValue
 	dbIdxAssetAddr     = 0x22 // (asset, addr, txNum)          => sateValue
 	dbIdxAssetAddrMemo = 0x23 // (asset, addr, addrTag, txNum) => sateValue
 	dbIdxUsers         = 0x24 // (userID) => txUID
 	dbIdxSourceTx      = 0x25 // (providerID, sourceID, txUID) => nil
 	dbIdxSourceAddr    = 0x26 // (providerID, sourceID, addr)  => total supply by addr
 	dbIdxInvites       = 0x27 // (userID, txNum)               => invitedUserID
-	dbIdxSrcInvites    = 0x28 // (userID, txNum)               => invitedUserID
+	dbIdxSrcInvites    = 0x28 // (providerID, userID)          => total referral rewards
 	dbIdxBalances      = 0x29 // (asset, addr)                 => balance
+	dbIdxPruned        = 0x2a // (asset, addr, addrTag)        => txUID of the newest pruned record
+	dbIdxRichList      = 0x2b // (asset, balance, addr)        => nil
+	dbIdxHolders       = 0x2c // (asset, balanceOrder)         => count of holders
+	dbIdxAddrSources   = 0x2d // (providerID, addr, sourceID)  => nil
+	dbIdxUserList      = 0x2e // (txUID)                       => userID
+	dbIdxNickPrefix    = 0x2f // (nickPrefix, nick)            => userID
+	dbIdxCounterparty  = 0x30 // (asset, addr, counterparty, txUID) => nil
+	dbIdxReservedNicks = 0x31 // (nick)                        => 1
 )
 
 var (
@@ -65,6 +82,7 @@ var (
 	errTxNotFound            = errors.New("tx not found")
 	errUserHasBeenRegistered = errors.New("user has been registered")
 	errUserNotFound          = errors.New("user not found")
+	ErrSourceNotFound        = errors.New("source not found")
 	ErrAddrNotFound          = errors.New("address not found")
 	errIncorrectAddress      = errors.New("incorrect address")
 	errIncorrectAssetVal     = errors.New("incorrect asset value")
@@ -81,12 +99,18 @@ func NewBlockchainStorage(cfg *blockchain.Config) (s *BlockchainStorage) {
 		cacheTxs:     gosync.NewCache(100000),
 		cacheIdxTx:   gosync.NewCache(30000),
 		Mempool:      mempool.NewStorage(),
+		newBlocks:    make(chan struct{}),
 	}
 
 	if cfg.VacuumDB {
 		s.db.Vacuum()
 	}
 
+	// apply genesis of db
+	if err := s.loadGenesis(); err != nil {
+		panic(err)
+	}
+
 	// query last block
 	if b, err := s.queryLastBlock(); err != nil {
 		panic(err)
@@ -98,6 +122,10 @@ func NewBlockchainStorage(cfg *blockchain.Config) (s *BlockchainStorage) {
 	if err := s.db.QueryValue(goldb.NewQuery(dbTabStat).Last(), &s.stat); err != nil {
 		panic(err)
 	}
+	// upgrade db-schema
+	if err := s.migrate(); err != nil {
+		panic(err)
+	}
 
 	return
 }
@@ -123,6 +151,10 @@ func (s *BlockchainStorage) AddMiddleware(fn Middleware) {
 	s.middleware = append(s.middleware, fn)
 }
 
+func (s *BlockchainStorage) AddCommitHandler(fn CommitHandler) {
+	s.onCommit = append(s.onCommit, fn)
+}
+
 func (s *BlockchainStorage) ChainTree() *patricia.Tree {
 	return patricia.NewTree(patricia.NewMemoryStorage(patricia.NewSubStorage(s.db, goldb.Key(dbTabChainTree))))
 }
@@ -134,16 +166,46 @@ func (s *BlockchainStorage) StateTree() *patricia.Tree {
 // State returns state struct from db
 func (s *BlockchainStorage) State() *state.State {
 	return state.NewState(s.Cfg.ChainID, func(a assets.Asset, addr crypto.Address) (v bignum.Int) {
-		if err := s.db.QueryValue(goldb.NewQuery(dbIdxAssetAddr, a, addr).Last(), &v); err != nil {
+		if _, err := s.db.GetVar(goldb.Key(dbIdxBalances, a, addr), &v); err != nil {
 			panic(err)
 		}
 		return
 	})
 }
 
+// InvalidBlockError is the error of block verification: header, signatures, txs or states of block are invalid.
+// Other errors of PutBlock are local errors of the node (db, i/o); the block can be put again.
+type InvalidBlockError struct {
+	Err error
+}
+
+func invalidBlock(err error) error {
+	return &InvalidBlockError{err}
+}
+
+func (e *InvalidBlockError) Error() string {
+	return e.Err.Error()
+}
+
+// IsInvalidBlock returns true if err is the error of block verification (see InvalidBlockError)
+func IsInvalidBlock(err error) bool {
+	_, ok := err.(*InvalidBlockError)
+	return ok
+}
+
 //----------------- put block --------------------------
 // open db.transaction; verify block; save block and index-records
 func (s *BlockchainStorage) PutBlock(blocks ...*blockchain.Block) error {
+	return s.putBlocks(blocks, true)
+}
+
+// PutVerifiedBlock saves blocks which txs have been already verified by caller (see blockchain.VerifyTxs).
+// Block headers and states of txs are verified as usual.
+func (s *BlockchainStorage) PutVerifiedBlock(blocks ...*blockchain.Block) error {
+	return s.putBlocks(blocks, false)
+}
+
+func (s *BlockchainStorage) putBlocks(blocks []*blockchain.Block, verifyTxs bool) error {
 	if len(blocks) == 0 {
 		return nil
 	}
@@ -154,12 +216,21 @@ func (s *BlockchainStorage) PutBlock(blocks ...*blockchain.Block) error {
 	// verify blocks
 	lastBlockHeader := s.lastBlock.BlockHeader
 	for _, block := range blocks {
-		if err := block.Verify(lastBlockHeader, s.Cfg); err != nil {
-			return err
+		if err := block.Verify(lastBlockHeader, s.Cfg); err == blockchain.ErrBlockFromFuture {
+			return err // clock of node can be behind; the block can be received again later
+		} else if err != nil {
+			return invalidBlock(err)
 		}
 		lastBlockHeader = block.BlockHeader
 	}
 
+	// verify signatures and objects of txs in parallel (txs states are verified serially by db transaction)
+	if verifyTxs && s.Cfg.VerifyTxsLevel >= blockchain.VerifyTxLevel1 {
+		if err := blockchain.VerifyTxs(s.Cfg, blockchain.BlocksTxs(blocks)); err != nil {
+			return invalidBlock(err)
+		}
+	}
+
 	var blockStat = s.stat
 	var txsIDs []uint64
 
@@ -170,172 +241,225 @@ func (s *BlockchainStorage) PutBlock(blocks ...*blockchain.Block) error {
 		chainTree := patricia.NewSubTree(tr, goldb.Key(dbTabChainTree))
 
 		for _, block := range blocks {
+			blockStat = s.putBlock(tr, stateTree, chainTree, block, blockStat)
 
-			// init new block statistic
-			blockStat = blockStat.New(block.Num, len(block.Txs))
+			for _, tx := range block.Txs {
+				txsIDs = append(txsIDs, tx.ID())
+			}
+		}
+	})
 
-			// add index on transactions
-			for txIdx, tx := range block.Txs {
+	if err != nil {
+		return err
+	}
 
-				txID := tx.ID()
-				txUID := encodeTxUID(block.Num, txIdx)
-				txsIDs = append(txsIDs, txID)
+	//--- success block commit ------
 
-				// check transaction by txID
-				if id, _ := tr.GetID(goldb.Key(dbIdxTxID, txID)); id != 0 {
-					tr.Fail(errTxHasBeenRegistered)
-				}
+	// refresh last block and totals info
+	s.mxR.Lock()
+	s.lastBlock = blocks[len(blocks)-1]
+	close(s.newBlocks)
+	s.newBlocks = make(chan struct{})
+	s.stat = blockStat
+	s.mxR.Unlock()
 
-				if s.Cfg.VerifyTxsLevel >= blockchain.VerifyTxLevel1 {
+	for _, block := range blocks {
+		s.cacheHeaders.Set(block.Num, block.BlockHeader)
+	}
 
-					//-- verify sender signature
-					if err := tx.Verify(s.Cfg); err != nil {
-						tr.Fail(err)
-					}
+	// remove txs from Mempool
+	s.Mempool.RemoveTxs(txsIDs)
 
-					//-- verify transaction state
-					// make state by dbTransaction
-					st := state.NewState(s.Cfg.ChainID, func(a assets.Asset, addr crypto.Address) (v bignum.Int) {
-						// get state from db
-						tr.QueryValue(goldb.NewQuery(dbIdxAssetAddr, a, addr).Last(), &v)
-						return
-					})
-
-					// execute transaction
-					stateUpdates, err := tx.Execute(st)
-					if err != nil {
-						tr.Fail(err)
-					}
+	for _, fn := range s.onCommit {
+		fn(blocks)
+	}
 
-					// compare result state
-					if !tx.StateUpdates.Equal(stateUpdates) {
-						tr.Fail(errIncorrectTxState)
-					}
-				}
+	return nil
+}
 
-				obj := tx.TxObject()
+// putBlock saves block, its txs and index-records by opened db-transaction; returns statistic of the block
+func (s *BlockchainStorage) putBlock(
+	tr *goldb.Transaction,
+	stateTree *patricia.Tree,
+	chainTree *patricia.Tree,
+	block *blockchain.Block,
+	blockStat *Statistic,
+) *Statistic {
+	// verify authority of miner by validator set of the previous block
+	if s.Cfg.Consensus == blockchain.ConsensusPoA {
+		s.verifyMiner(tr, block)
+	}
 
-				switch tx.Type {
+	// init new block statistic
+	blockStat = blockStat.New(block.Num, len(block.Txs))
 
-				case object.TxTypeEmission:
-					if emission, ok := obj.(*object.Emission); ok {
-						if emission.IsPrimaryEmission() {
-							for _, out := range emission.Outs {
-								// set last tx by source
-								tr.Put(goldb.Key(dbIdxSourceTx, emission.Asset, out.SourceID, txUID), nil)
+	// total primary emission of block
+	var emitted bignum.Int
 
-								// increment last tx by source
-								if out.Delta > 0 {
-									delta := emission.Amount(out.Delta)
-									tr.IncrementBig(goldb.Key(dbIdxSourceAddr, emission.Asset, out.SourceID, out.Address), delta.BigInt())
-								}
-							}
-						}
-						// else if emission.IsReferralReward() {
-						//	for _, out := range emission.Outs {
-						//		if out.Delta > 0 {
-						//			delta := emission.Amount(out.Delta)
-						//			tr.IncrementBig(goldb.Key(dbIdxSrcInvites, emission.Asset, out.SourceID, out.Address), delta.BigInt())
-						//		}
-						//	}
-
-						blockStat.IncSupplyStat(emission) // refresh totals statistic
-					}
+	// add index on transactions
+	for txIdx, tx := range block.Txs {
 
-				case object.TxTypeTransfer:
-					if tr, ok := obj.(*object.Transfer); ok {
-						blockStat.IncVolumeStat(tr) // refresh statistic of total transfers
-					}
+		txID := tx.ID()
+		txUID := encodeTxUID(block.Num, txIdx)
 
-				case object.TxTypeUser:
-					userID := tx.Sender.ID()
+		// check transaction by txID
+		if id, _ := tr.GetID(goldb.Key(dbIdxTxID, txID)); id != 0 {
+			tr.Fail(invalidBlock(errTxHasBeenRegistered))
+		}
 
-					// get user by userID
-					if usrTxUID, _ := tr.GetID(goldb.Key(dbIdxUsers, userID)); usrTxUID != 0 {
-						tr.Fail(errUserHasBeenRegistered)
-					}
-					tr.PutID(goldb.Key(dbIdxUsers, userID), txUID)
+		if s.Cfg.VerifyTxsLevel >= blockchain.VerifyTxLevel1 {
 
-					if usr, ok := obj.(*object.User); ok && usr.ReferrerID != 0 {
-						tr.PutID(goldb.Key(dbIdxInvites, usr.ReferrerID, txUID), txUID)
-					}
+			//-- verify transaction state (sender signature has been verified before db transaction)
+			// make state by dbTransaction
+			st := state.NewState(s.Cfg.ChainID, func(a assets.Asset, addr crypto.Address) (v bignum.Int) {
+				// get state from db
+				tr.GetVar(goldb.Key(dbIdxBalances, a, addr), &v)
+				return
+			})
 
-					blockStat.Users++ // increment users counter
-				}
+			// execute transaction
+			stateUpdates, err := tx.Execute(st)
+			if err != nil {
+				tr.Fail(invalidBlock(err))
+			}
 
-				// put transaction data
-				tr.PutVar(goldb.Key(dbTabTxs, block.Num, txIdx), tx)
+			// compare result state
+			if !tx.StateUpdates.Equal(stateUpdates) {
+				tr.Fail(invalidBlock(errIncorrectTxState))
+			}
+		}
 
-				// put index transaction by txID
-				tr.PutID(goldb.Key(dbIdxTxID, txID), txUID)
+		obj := tx.TxObject()
 
-				// save state to db-storage
-				for stIdx, v := range tx.StateUpdates {
-					if v.ChainID == s.Cfg.ChainID {
-						stateTree.Put(v.StateKey(), v.Balance.Bytes())
+		switch tx.Type {
 
-						if v.Asset.IsName() {
-							tr.PutVar(goldb.Key(dbIdxAsset, v.Asset, txUID, stIdx, v.Address), v.Balance)
-						}
+		case object.TxTypeEmission:
+			if emission, ok := obj.(*object.Emission); ok {
+				s.verifyEmissionSchedule(tr, block, emission, &emitted)
 
-						tr.PutVar(goldb.Key(dbIdxAssetAddr, v.Asset, v.Address, txUID, stIdx), v.Balance)
+				if emission.IsPrimaryEmission() {
+					if s.Cfg.VerifyTxsLevel >= blockchain.VerifyTxLevel1 {
+						s.verifyPrimaryEmission(tr, block, emission)
+					}
 
-						if !v.Balance.IsZero() {
-							tr.PutVar(goldb.Key(dbIdxBalances, v.Asset, v.Address), v.Balance)
-						} else {
-							tr.Delete(goldb.Key(dbIdxBalances, v.Asset, v.Address))
-						}
-						if v.Memo != 0 { // change state with memo
-							tr.PutVar(goldb.Key(dbIdxAssetAddrMemo, v.Asset, v.Address, v.Memo, txUID, stIdx), v.Balance)
+					for _, out := range emission.Outs {
+						// set last tx by source
+						tr.Put(goldb.Key(dbIdxSourceTx, emission.Asset, out.SourceID, txUID), nil)
+
+						// increment last tx by source
+						if out.Delta > 0 {
+							delta := emission.Amount(out.Delta)
+							tr.IncrementBig(goldb.Key(dbIdxSourceAddr, emission.Asset, out.SourceID, out.Address), delta.BigInt())
+							tr.Put(goldb.Key(dbIdxAddrSources, emission.Asset, out.Address, out.SourceID), nil)
 						}
 					}
+				} else if emission.IsReferralReward() {
+					verifyReferralReward(tr, emission)
+					putReferralRewards(tr, emission) // increment totals of referral rewards
+				} else if emission.IsPoSMinting() {
+					s.verifyPoSMinting(tr, block, emission)
+				}
+
+				blockStat.IncEmissionStat(emission) // refresh totals statistic
+			}
+
+		case object.TxTypeTransfer:
+			if tr, ok := obj.(*object.Transfer); ok {
+				blockStat.IncVolumeStat(tr) // refresh statistic of total transfers
+			}
+
+		case object.TxTypeUser:
+			userID := tx.Sender.ID()
+
+			// username reserved by genesis can be registered by master key only
+			if usr, ok := obj.(*object.User); ok && usr != nil && !tx.Sender.Equal(config.MasterPublicKey) {
+				if isReservedNick(tr, usr.Nick) {
+					tr.Fail(invalidBlock(errUsernameReserved))
 				}
 			}
 
-			// verify state root
-			if stateRoot, _ := stateTree.Root(); !bytes.Equal(block.StateRoot, stateRoot) {
-				tr.Fail(errIncorrectStateRoot)
+			// get user by userID
+			if usrTxUID, _ := tr.GetID(goldb.Key(dbIdxUsers, userID)); usrTxUID != 0 {
+				tr.Fail(invalidBlock(errUserHasBeenRegistered))
 			}
+			tr.PutID(goldb.Key(dbIdxUsers, userID), txUID)
 
-			// verify chain root
-			chainTree.PutVar(block.Num, block.Hash())
-			if chainRoot, _ := chainTree.Root(); !bytes.Equal(block.ChainRoot, chainRoot) {
-				tr.Fail(errIncorrectChainRoot)
+			if usr, ok := obj.(*object.User); ok && usr != nil {
+				if usr.ReferrerID != 0 {
+					tr.PutID(goldb.Key(dbIdxInvites, usr.ReferrerID, txUID), txUID)
+				}
+				putUserIndexes(tr, txUID, userID, usr)
 			}
 
-			// put block
-			tr.PutVar(goldb.Key(dbTabHeaders, block.Num), block.BlockHeader)
+			blockStat.Users++ // increment users counter
+		}
 
-			// save totals
-			tr.PutVar(goldb.Key(dbTabStat, block.Timestamp, block.Num), blockStat)
+		// put transaction data
+		tr.PutVar(goldb.Key(dbTabTxs, block.Num, txIdx), tx)
 
-			// middleware for each block
-			for _, fn := range s.middleware {
-				fn(tr, block)
+		// put index transfers by counterparties
+		s.putCounterparties(tr, tx, txUID)
+
+		// put index transaction by txID
+		tr.PutID(goldb.Key(dbIdxTxID, txID), txUID)
+
+		// save state to db-storage
+		for stIdx, v := range tx.StateUpdates {
+			if v.ChainID == s.Cfg.ChainID {
+				stateTree.Put(v.StateKey(), v.Balance.Bytes())
+
+				tr.PutVar(goldb.Key(dbIdxAsset, v.Asset, txUID, stIdx, v.Address), v.Balance)
+				tr.PutVar(goldb.Key(dbIdxAssetAddr, v.Asset, v.Address, txUID, stIdx), v.Balance)
+				s.pruneHistory(tr, block.Num, v.Asset, v.Address, 0)
+
+				var oldBalance bignum.Int
+				tr.GetVar(goldb.Key(dbIdxBalances, v.Asset, v.Address), &oldBalance)
+				updateRichList(tr, v.Asset, v.Address, oldBalance, v.Balance)
+				blockStat.IncSupplyStat(v.Asset, oldBalance, v.Balance)
+
+				if !v.Balance.IsZero() {
+					tr.PutVar(goldb.Key(dbIdxBalances, v.Asset, v.Address), v.Balance)
+				} else {
+					tr.Delete(goldb.Key(dbIdxBalances, v.Asset, v.Address))
+				}
+				if v.Memo != 0 { // change state with memo
+					tr.PutVar(goldb.Key(dbIdxAssetAddrMemo, v.Asset, v.Address, v.Memo, txUID, stIdx), v.Balance)
+					s.pruneHistory(tr, block.Num, v.Asset, v.Address, v.Memo)
+				}
 			}
 		}
-	})
+	}
 
-	if err != nil {
-		return err
+	// verify state root
+	if stateRoot, _ := stateTree.Root(); !bytes.Equal(block.StateRoot, stateRoot) {
+		tr.Fail(invalidBlock(errIncorrectStateRoot))
 	}
 
-	//--- success block commit ------
+	// verify chain root
+	chainTree.PutVar(block.Num, block.Hash())
+	if chainRoot, _ := chainTree.Root(); !bytes.Equal(block.ChainRoot, chainRoot) {
+		tr.Fail(invalidBlock(errIncorrectChainRoot))
+	}
 
-	// refresh last block and totals info
-	s.mxR.Lock()
-	s.lastBlock = blocks[len(blocks)-1]
-	s.stat = blockStat
-	s.mxR.Unlock()
+	// put block
+	tr.PutVar(goldb.Key(dbTabHeaders, block.Num), block.BlockHeader)
 
-	for _, block := range blocks {
-		s.cacheHeaders.Set(block.Num, block.BlockHeader)
+	// save totals
+	tr.PutVar(goldb.Key(dbTabStat, block.Timestamp, block.Num), blockStat)
+
+	// middleware for each block
+	for _, fn := range s.middleware {
+		fn(tr, block)
 	}
 
-	// remove txs from Mempool
-	s.Mempool.RemoveTxs(txsIDs)
+	return blockStat
+}
 
-	return nil
+// NewBlocksNotify returns channel which is closed on commit of the next blocks
+func (s *BlockchainStorage) NewBlocksNotify() <-chan struct{} {
+	s.mxR.RLock()
+	defer s.mxR.RUnlock()
+	return s.newBlocks
 }
 
 func (s *BlockchainStorage) LastBlock() *blockchain.Block {
@@ -368,6 +492,34 @@ func (s *BlockchainStorage) TotalsAt(t time.Time) (totals *Statistic, err error)
 	return
 }
 
+// StatSeries returns statistic at the end of each interval in time range [from, to)
+func (s *BlockchainStorage) StatSeries(from, to time.Time, interval time.Duration) (series []*StatPoint, err error) {
+	if interval <= 0 || to.Sub(from)/interval > maxStatSeriesPoints {
+		return nil, errStatSeriesTooLong
+	}
+	for t := from; t.Before(to); t = t.Add(interval) {
+		end := t.Add(interval)
+		totals, err := s.TotalsAt(end)
+		if err != nil {
+			return nil, err
+		}
+		if totals == nil {
+			totals = &Statistic{}
+		}
+		series = append(series, &StatPoint{end.UnixNano() / 1e3, totals})
+	}
+	return
+}
+
+// BlockNumAt returns num of the last block created before time t
+func (s *BlockchainStorage) BlockNumAt(t time.Time) (uint64, error) {
+	totals, err := s.TotalsAt(t)
+	if err != nil || totals == nil {
+		return 0, err
+	}
+	return totals.Blocks, nil
+}
+
 func (s *BlockchainStorage) TotalSupply(asset assets.Asset) bignum.Int {
 	return s.Totals().CoinStat(asset).Supply
 }
@@ -537,6 +689,9 @@ func (s *BlockchainStorage) transactionByIdxKey(idxKey []byte) (tx *blockchain.T
 		return
 	}
 	tx, err = s.transactionByUID(txUID)
+	if err == errTxNotFound && s.CheckFullIndexes() == ErrIncompleteIndex { // tx before snapshot
+		return nil, ErrIncompleteIndex
+	}
 	if tx != nil {
 		s.cacheIdxTx.Set(idxKey, tx)
 	}
@@ -592,12 +747,124 @@ func (s *BlockchainStorage) FetchTransactionsByAddr(
 	txType int,
 	fn func(tx *blockchain.Transaction, val bignum.Int) error,
 ) error {
+	_, err := s.FetchFilteredTransactionsByAddr(asset, addr, memo, &Cursor{TxUID: offset}, limit, orderDesc, NewTxFilter(txType), fn)
+	return err
+}
+
+// FetchFilteredTransactionsByAddr fetches transactions of address matched the filter.
+// Fetching starts after the record of cursor (nil - from the beginning).
+// val is the state of address after tx (zero if transactions are fetched by counterparty).
+// Returns the cursor of the next page, or nil if there are no more records.
+func (s *BlockchainStorage) FetchFilteredTransactionsByAddr(
+	asset assets.Asset,
+	addr crypto.Address,
+	memo uint64,
+	cur *Cursor,
+	limit int64,
+	orderDesc bool,
+	f *TxFilter,
+	fn func(tx *blockchain.Transaction, val bignum.Int) error,
+) (next *Cursor, err error) {
+	var pos Cursor
+	if cur != nil {
+		pos = *cur
+	}
+	byCounterparty := !f.Counterparty.Empty()
+	if byCounterparty {
+		err = s.CheckFullIndexes()
+	} else {
+		err = s.CheckHistory(asset, addr, memo, pos.TxUID, orderDesc)
+	}
+	if err != nil {
+		return
+	}
+	minUID, maxUID, err := s.txUIDRange(f)
+	if err != nil {
+		return
+	}
 	var q *goldb.Query
-	if memo == 0 { // fetch transactions by address
+	if byCounterparty { // fetch transfers by address and counterparty
+		q = goldb.NewQuery(dbIdxCounterparty, asset, addr, f.Counterparty)
+	} else if memo == 0 { // fetch transactions by address
 		q = goldb.NewQuery(dbIdxAssetAddr, asset, addr)
 	} else { // fetch transactions by address+memo
 		q = goldb.NewQuery(dbIdxAssetAddrMemo, asset, addr, memo)
 	}
+	if !orderDesc && minUID > 0 && pos.TxUID < minUID {
+		pos = Cursor{TxUID: minUID - 1}
+	}
+	if orderDesc && maxUID > 0 && (pos.TxUID == 0 || pos.TxUID > maxUID) {
+		pos = Cursor{TxUID: maxUID}
+	}
+	if pos.TxUID > 0 {
+		if byCounterparty {
+			q.Offset(pos.TxUID)
+		} else {
+			q.Offset(pos.TxUID, pos.StIdx)
+		}
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	q.Order(orderDesc)
+
+	txUID := pos.TxUID // records with txUID of cursor have been fetched
+	var outOfRange bool
+	err = s.db.Fetch(q, func(rec goldb.Record) error {
+		if limit <= 0 {
+			next = &Cursor{TxUID: pos.TxUID, StIdx: pos.StIdx}
+			return goldb.Break
+		}
+		var _memo, _txUID uint64
+		var _stIdx int
+		var counterparty crypto.Address
+		if byCounterparty {
+			rec.MustDecodeKey(&asset, &addr, &counterparty, &_txUID)
+		} else if memo == 0 {
+			rec.MustDecodeKey(&asset, &addr, &_txUID, &_stIdx)
+		} else {
+			rec.MustDecodeKey(&asset, &addr, &_memo, &_txUID, &_stIdx)
+		}
+		pos.TxUID, pos.StIdx = _txUID, _stIdx
+		if txUID == _txUID { // exclude multiple records with the same txUID
+			return nil
+		}
+		txUID = _txUID
+		if orderDesc && txUID < minUID || !orderDesc && maxUID > 0 && txUID >= maxUID {
+			outOfRange = true
+			return goldb.Break
+		}
+		tx, err := s.transactionByUID(txUID)
+		if err != nil {
+			return err
+		}
+		if !f.match(tx, asset, addr) || byCounterparty && memo != 0 && !txChangesMemo(tx, asset, addr, memo) {
+			return nil
+		}
+		var v bignum.Int
+		if !byCounterparty {
+			rec.MustDecode(&v)
+		}
+		limit--
+		return fn(tx, v)
+	})
+	if err == nil && orderDesc && limit > 0 && !outOfRange && !byCounterparty { // all actual records are fetched; check the rest of history
+		err = s.CheckHistory(asset, addr, memo, 1, true)
+	}
+	return
+}
+
+// FetchTransactionsByAsset fetches transactions changed state of asset (feed of all state changes of asset).
+// Returns ErrHistoryPruned when the feed reaches rows before the prune horizon (see CheckAssetFeed).
+func (s *BlockchainStorage) FetchTransactionsByAsset(
+	asset assets.Asset,
+	offset uint64,
+	limit int64,
+	orderDesc bool,
+	txType int,
+	fn func(tx *blockchain.Transaction) error,
+) error {
+	q := goldb.NewQuery(dbIdxAsset, asset)
 	if offset > 0 {
 		q.Offset(offset)
 	}
@@ -606,16 +873,19 @@ func (s *BlockchainStorage) FetchTransactionsByAddr(
 	}
 	q.Order(orderDesc)
 
+	var horizon uint64
+	if !asset.IsName() {
+		horizon = s.pruneHorizon(s.LastBlock().Num)
+	}
 	var txUID uint64
 	return s.db.Fetch(q, func(rec goldb.Record) error {
 		if limit <= 0 {
 			return goldb.Break
 		}
-		var _memo, _txUID uint64
-		if memo == 0 {
-			rec.MustDecodeKey(&asset, &addr, &_txUID)
-		} else {
-			rec.MustDecodeKey(&asset, &addr, &_memo, &_txUID)
+		var _txUID uint64
+		rec.MustDecodeKey(&asset, &_txUID)
+		if _txUID < horizon {
+			return ErrHistoryPruned
 		}
 		if txUID == _txUID { // exclude multiple records with the same txUID
 			return nil
@@ -628,10 +898,22 @@ func (s *BlockchainStorage) FetchTransactionsByAddr(
 		if txType >= 0 && int(tx.Type) != txType {
 			return nil
 		}
-		var v bignum.Int
-		rec.MustDecode(&v)
 		limit--
-		return fn(tx, v)
+		return fn(tx)
+	})
+}
+
+// migrateAssetFeed adds state changes of coins and tokens to dbIdxAsset (previously only name-assets were indexed)
+func migrateAssetFeed(s *BlockchainStorage, ver int) error {
+	return s.migrateBlocks(ver, func(tr *goldb.Transaction, block *blockchain.Block) {
+		for txIdx, tx := range block.Txs {
+			txUID := encodeTxUID(block.Num, txIdx)
+			for stIdx, v := range tx.StateUpdates {
+				if v.ChainID == s.Cfg.ChainID && !v.Asset.IsName() {
+					tr.PutVar(goldb.Key(dbIdxAsset, v.Asset, txUID, stIdx, v.Address), v.Balance)
+				}
+			}
+		}
 	})
 }
 
@@ -804,7 +1086,21 @@ func (s *BlockchainStorage) NameAddress(name string) (addr crypto.Address, txUID
 }
 
 func (s *BlockchainStorage) GetBalance(addr crypto.Address, asset assets.Asset) (balance bignum.Int, lastTx *blockchain.Transaction, err error) {
-	lastTx, balance, err = s.QueryTransaction(asset, addr, 0, 0, true)
+	if _, err = s.db.GetVar(goldb.Key(dbIdxBalances, asset, addr), &balance); err != nil {
+		return
+	}
+	lastTx, err = s.LastTx(addr, 0, asset)
+	return
+}
+
+// BalanceAt returns balance of address and its last tx at the block height blockNum.
+// Balance is taken from the last state change of address by index dbIdxAssetAddr (ordered by txUID);
+// if the address has not been changed till blockNum, balance is the initial balance of genesis (lastTx is nil).
+func (s *BlockchainStorage) BalanceAt(addr crypto.Address, asset assets.Asset, blockNum uint64) (balance bignum.Int, lastTx *blockchain.Transaction, err error) {
+	if lastTx, balance, err = s.QueryTransaction(asset, addr, 0, encodeTxUID(blockNum+1, 0), true); err != nil || lastTx != nil {
+		return
+	}
+	balance, err = s.genesisBalance(asset, addr)
 	return
 }
 
@@ -814,21 +1110,48 @@ func (s *BlockchainStorage) LastTx(addr crypto.Address, memo uint64, asset asset
 }
 
 func (s *BlockchainStorage) SourceTotalSupply(asset assets.Asset, addr crypto.Address, sourceID string) (total bignum.Int, err error) {
+	if err = s.CheckFullIndexes(); err != nil {
+		return
+	}
 	_, err = s.db.GetVar(goldb.Key(dbIdxSourceAddr, asset, sourceID, addr), &total)
 	return
 }
 
 func (s *BlockchainStorage) LastSourceData(asset assets.Asset, sourceID string) (curLikes int64, curAddr crypto.Address, err error) {
-	// todo: ? use other index, remove lastSourceTx. (get curAddr, curLikes by asset and sourceID)
-
-	_, out, err := s.lastSourceTx(asset, sourceID)
-	if err == nil && out != nil {
-		curLikes = out.SourceValue
-		curAddr = out.Address
+	if err = s.CheckFullIndexes(); err != nil {
+		return
 	}
+	curLikes, curAddr, _, err = lastSourceData(s.db, asset, sourceID)
 	return
 }
 
+// lastSourceData returns the last value and address of media-source by db-context (storage or opened transaction).
+// ok is false if there are no emissions by the source.
+func lastSourceData(c dbContext, asset assets.Asset, sourceID string) (curLikes int64, curAddr crypto.Address, ok bool, err error) {
+	var txUID uint64
+	err = c.Fetch(goldb.NewQuery(dbIdxSourceTx, asset, sourceID).Last(), func(rec goldb.Record) error {
+		rec.MustDecodeKey(&asset, new(string), &txUID)
+		return nil
+	})
+	if err != nil || txUID == 0 {
+		return
+	}
+	var tx *blockchain.Transaction
+	blockNum, txIdx := decodeTxUID(txUID)
+	if _, err = c.GetVar(goldb.Key(dbTabTxs, blockNum, txIdx), &tx); err != nil {
+		return
+	}
+	if tx == nil {
+		return 0, crypto.NilAddress, false, errTxNotFound
+	}
+	if emission, _ := tx.TxObject().(*object.Emission); emission != nil {
+		if out := emission.OutBySrc(sourceID); out != nil {
+			curLikes, curAddr = out.SourceValue, out.Address
+		}
+	}
+	return curLikes, curAddr, true, nil
+}
+
 func (s *BlockchainStorage) lastSourceTx(asset assets.Asset, sourceID string) (tx *blockchain.Transaction, txOut *object.EmissionOut, err error) {
 	err = s.db.Fetch(goldb.NewQuery(dbIdxSourceTx, asset, sourceID).Last(), func(rec goldb.Record) (err error) {
 		var txUID uint64
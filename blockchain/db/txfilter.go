@@ -0,0 +1,152 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+// directions of txs
+const (
+	TxDirAny = 0 //
+	TxDirIn  = 1 // incoming txs
+	TxDirOut = 2 // outgoing txs
+)
+
+// TxFilter is the filter of address transactions
+type TxFilter struct {
+	TxType       int            // -1 - any type
+	Direction    int            // TxDirAny | TxDirIn | TxDirOut
+	Counterparty crypto.Address // sender of incoming or receiver of outgoing transfer; empty - any
+	MinAmount    bignum.Int     // zero - no limit
+	MaxAmount    bignum.Int     // zero - no limit
+	FromTime     time.Time      // zero - no limit
+	ToTime       time.Time      // zero - no limit (exclusive)
+	Comment      string         // substring of tx comment (case insensitive)
+}
+
+func NewTxFilter(txType int) *TxFilter {
+	return &TxFilter{TxType: txType}
+}
+
+func (f *TxFilter) needTxAmount() bool {
+	return f.Direction != TxDirAny || !f.MinAmount.IsZero() || !f.MaxAmount.IsZero()
+}
+
+// txUIDRange returns range [min, max) of txUID by time range of filter (max=0 - no limit)
+func (s *BlockchainStorage) txUIDRange(f *TxFilter) (min, max uint64, err error) {
+	if !f.FromTime.IsZero() {
+		num, err := s.BlockNumAt(f.FromTime)
+		if err != nil {
+			return 0, 0, err
+		}
+		min = encodeTxUID(num+1, 0)
+	}
+	if !f.ToTime.IsZero() {
+		num, err := s.BlockNumAt(f.ToTime)
+		if err != nil {
+			return 0, 0, err
+		}
+		max = encodeTxUID(num+1, 0)
+	}
+	return
+}
+
+// match returns true if transaction of address matches the filter (excluding time range)
+func (f *TxFilter) match(tx *blockchain.Transaction, asset assets.Asset, addr crypto.Address) bool {
+	if f.TxType >= 0 && int(tx.Type) != f.TxType {
+		return false
+	}
+	if f.Comment != "" && !strings.Contains(strings.ToLower(txComment(tx)), strings.ToLower(f.Comment)) {
+		return false
+	}
+	if f.needTxAmount() {
+		dir, amount := txAmount(tx, asset, addr)
+		if f.Direction != TxDirAny && f.Direction != dir {
+			return false
+		}
+		if !f.MinAmount.IsZero() && amount.Cmp(f.MinAmount) < 0 {
+			return false
+		}
+		if !f.MaxAmount.IsZero() && amount.Cmp(f.MaxAmount) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func txChangesMemo(tx *blockchain.Transaction, asset assets.Asset, addr crypto.Address, memo uint64) bool {
+	for _, v := range tx.StateUpdates {
+		if v.Memo == memo && v.Address == addr && v.Asset.Equal(asset) {
+			return true
+		}
+	}
+	return false
+}
+
+func txComment(tx *blockchain.Transaction) string {
+	switch obj := tx.TxObject().(type) {
+	case *object.Transfer:
+		return obj.Comment
+	case *object.Emission:
+		return obj.Comment
+	}
+	return ""
+}
+
+// txAmount returns direction of transaction and amount of asset transferred from/to address
+func txAmount(tx *blockchain.Transaction, asset assets.Asset, addr crypto.Address) (dir int, amount bignum.Int) {
+	switch obj := tx.TxObject().(type) {
+	case *object.Transfer:
+		out := tx.SenderAddress() == addr
+		for _, o := range obj.Outs {
+			if o.Asset.Equal(asset) && (out || o.To == addr) {
+				amount = amount.Add(o.Amount)
+			}
+		}
+		if out {
+			return TxDirOut, amount
+		}
+	case *object.Emission:
+		if obj.Asset.Equal(asset) {
+			var delta int64
+			for _, o := range obj.Outs {
+				if o.Address == addr {
+					delta += o.Delta
+				}
+			}
+			amount = obj.Amount(delta)
+		}
+	}
+	return TxDirIn, amount
+}
+
+// putCounterparties adds transfer to index of counterparties of sender and receivers
+func (s *BlockchainStorage) putCounterparties(tr *goldb.Transaction, tx *blockchain.Transaction, txUID uint64) {
+	obj, ok := tx.TxObject().(*object.Transfer)
+	if !ok || obj == nil {
+		return
+	}
+	sender := tx.SenderAddress()
+	for _, out := range obj.Outs {
+		if out.ToChainID == s.Cfg.ChainID {
+			tr.Put(goldb.Key(dbIdxCounterparty, out.Asset, sender, out.To, txUID), nil)
+			tr.Put(goldb.Key(dbIdxCounterparty, out.Asset, out.To, sender, txUID), nil)
+		}
+	}
+}
+
+// migrateCounterparties builds index of counterparties by stored transfers
+func migrateCounterparties(s *BlockchainStorage, ver int) error {
+	return s.migrateBlocks(ver, func(tr *goldb.Transaction, block *blockchain.Block) {
+		for txIdx, tx := range block.Txs {
+			s.putCounterparties(tr, tx, encodeTxUID(block.Num, txIdx))
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxFilter_match(t *testing.T) {
+	cfg := &blockchain.Config{ChainID: 1}
+	alice := crypto.NewPrivateKeyBySecret("alice")
+	bob := crypto.NewPrivateKeyBySecret("bob").PublicKey.Address()
+	tx := object.NewSimpleTransfer(cfg, alice, bob, bignum.NewInt(100), assets.Default, "Payment for Order #1", 0, 0)
+
+	f := NewTxFilter(-1)
+	f.Direction = TxDirOut
+	assert.True(t, f.match(tx, assets.Default, alice.PublicKey.Address()))
+	assert.False(t, f.match(tx, assets.Default, bob))
+
+	f = NewTxFilter(object.TxTypeTransfer)
+	f.MinAmount = bignum.NewInt(100)
+	f.MaxAmount = bignum.NewInt(200)
+	f.Comment = "order #1"
+	assert.True(t, f.match(tx, assets.Default, bob))
+
+	f.MinAmount = bignum.NewInt(101)
+	assert.False(t, f.match(tx, assets.Default, bob))
+
+	f = NewTxFilter(object.TxTypeEmission)
+	assert.False(t, f.match(tx, assets.Default, bob))
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+// putUserIndexes adds user to the list of users ordered by registration and to the index of nick prefixes
+func putUserIndexes(tr *goldb.Transaction, txUID, userID uint64, u *object.User) {
+	tr.PutID(goldb.Key(dbIdxUserList, txUID), userID)
+	for i := 1; i <= len(u.Nick); i++ {
+		tr.PutID(goldb.Key(dbIdxNickPrefix, u.Nick[:i], u.Nick), userID)
+	}
+}
+
+// FetchUsers fetches users ordered by registration; offset is txUID of user registration
+func (s *BlockchainStorage) FetchUsers(
+	offset uint64,
+	limit int64,
+	orderDesc bool,
+	fn func(tx *blockchain.Transaction, u *object.User) error,
+) error {
+	q := goldb.NewQuery(dbIdxUserList)
+	if offset > 0 {
+		q.Offset(offset)
+	}
+	if limit > 0 {
+		q.Limit(limit)
+	}
+	q.Order(orderDesc)
+	return s.db.Fetch(q, func(rec goldb.Record) error {
+		var txUID uint64
+		rec.MustDecodeKey(&txUID)
+		tx, err := s.transactionByUID(txUID)
+		if err != nil {
+			return err
+		}
+		if u, ok := tx.TxObject().(*object.User); ok && u != nil {
+			return fn(tx, u)
+		}
+		return nil
+	})
+}
+
+// SearchUsers fetches users by prefix of nick; shorter nicks are fetched first
+func (s *BlockchainStorage) SearchUsers(
+	prefix string,
+	limit int64,
+	fn func(tx *blockchain.Transaction, u *object.User) error,
+) error {
+	prefix = strings.ToLower(strings.TrimPrefix(prefix, "@"))
+	if prefix == "" {
+		return nil
+	}
+	q := goldb.NewQuery(dbIdxNickPrefix, prefix)
+	if limit > 0 {
+		q.Limit(limit)
+	}
+	return s.db.Fetch(q, func(rec goldb.Record) error {
+		var userID uint64
+		rec.MustDecode(&userID)
+		tx, u, err := s.UserByID(userID)
+		if err != nil || tx == nil {
+			return err
+		}
+		return fn(tx, u)
+	})
+}
+
+// migrateUserIndexes builds the list of users and the index of nick prefixes by stored user registrations
+func migrateUserIndexes(s *BlockchainStorage, ver int) error {
+	return s.migrateBlocks(ver, func(tr *goldb.Transaction, block *blockchain.Block) {
+		for txIdx, tx := range block.Txs {
+			if u, ok := tx.TxObject().(*object.User); ok && u != nil {
+				putUserIndexes(tr, encodeTxUID(block.Num, txIdx), tx.Sender.ID(), u)
+			}
+		}
+	})
+}
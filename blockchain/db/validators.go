@@ -0,0 +1,63 @@
+package db
+
+import (
+	"github.com/denisskin/goldb"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/crypto"
+)
+
+// Validators returns validator set of proof-of-authority consensus (addresses ordered by address)
+func (s *BlockchainStorage) Validators() ([]crypto.Address, error) {
+	return fetchValidators(s.db)
+}
+
+// ValidatorSet is the state of proof-of-authority consensus
+type ValidatorSet struct {
+	Consensus  int              `json:"consensus"`   // see blockchain.Config.Consensus
+	Validators []crypto.Address `json:"validators"`  // ordered by address
+	NextLeader crypto.Address   `json:"next_leader"` // validator authorized to sign the next block (empty - master key)
+}
+
+func (s *BlockchainStorage) ValidatorSet() (vs *ValidatorSet, err error) {
+	vs = &ValidatorSet{Consensus: s.Cfg.Consensus}
+	if vs.Validators, err = s.Validators(); err != nil {
+		return nil, err
+	}
+	if s.Cfg.Consensus == blockchain.ConsensusPoA {
+		last := s.LastBlock()
+		vs.NextLeader = blockchain.Leader(vs.Validators, last.Num+1, blockchain.Slot(s.Cfg, last.Timestamp, blockchain.Timestamp()))
+	}
+	return
+}
+
+// verifyMiner verifies authority of block miner by validator set of the opened db-transaction
+func (s *BlockchainStorage) verifyMiner(tr *goldb.Transaction, block *blockchain.Block) {
+	validators, err := fetchValidators(tr)
+	if err != nil {
+		tr.Fail(err)
+	}
+	pre := blockchain.GenesisBlockHeader(s.Cfg)
+	if block.Num > 1 { // header of the previous block is stored by the same db-transaction in case of batch
+		pre = new(blockchain.BlockHeader)
+		if ok, err := tr.GetVar(goldb.Key(dbTabHeaders, block.Num-1), pre); err != nil {
+			tr.Fail(err)
+		} else if !ok {
+			tr.Fail(ErrBlockNotFound)
+		}
+	}
+	if err = block.VerifyMiner(s.Cfg, validators, pre); err != nil {
+		tr.Fail(invalidBlock(err))
+	}
+}
+
+func fetchValidators(c dbContext) (validators []crypto.Address, err error) {
+	err = c.Fetch(goldb.NewQuery(dbIdxBalances, assets.Validators), func(rec goldb.Record) error {
+		var asset assets.Asset
+		var addr crypto.Address
+		rec.MustDecodeKey(&asset, &addr)
+		validators = append(validators, addr)
+		return nil
+	})
+	return
+}
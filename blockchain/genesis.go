@@ -1,8 +1,164 @@
 package blockchain
 
-import "github.com/likecoin-pro/likecoin/config"
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain/state"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/crypto/patricia"
+)
+
+var (
+	ErrGenesisEmptyKey          = errors.New("genesis: empty master or emission key")
+	ErrGenesisIncorrectBalance  = errors.New("genesis: incorrect initial balance")
+	ErrGenesisDuplicateBalance  = errors.New("genesis: duplicate initial balance")
+	ErrGenesisIncorrectUsername = errors.New("genesis: incorrect reserved username")
+)
+
+// Genesis is the configuration of new blockchain (block #0)
+type Genesis struct {
+	Network     int               `json:"network"`             // networkID
+	ChainID     uint64            `json:"chain"`               //
+	Timestamp   int64             `json:"timestamp"`           // timestamp of genesis block in µsec
+	Consensus   int               `json:"consensus"`           // see Config.Consensus
+	SlotTimeout int64             `json:"slot_timeout"`        // slot timeout of proof-of-authority consensus in seconds (0 - by config)
+	PoSRate     int64             `json:"pos_rate"`            // see Config.PoSRate
+	PoSMinAge   int64             `json:"pos_min_stake_age"`   // min age of stake for proof-of-stake minting in seconds (0 - by config)
+	Schedule    EmissionSchedule  `json:"emission_schedule"`   // published schedule of like rates and caps of primary emission
+	DeltaFrom   uint64            `json:"emission_delta_from"` // see Config.EmissionDeltaFrom
+	MasterKey   *crypto.PublicKey `json:"master_key"`          // signer of blocks and governance txs
+	EmissionKey *crypto.PublicKey `json:"emission_key"`        // signer of emission txs
+	Validators  []crypto.Address  `json:"validators"`          // initial validator set of proof-of-authority consensus
+	Balances    []*GenesisBalance `json:"balances"`            // initial balances
+	Usernames   []string          `json:"reserved_usernames"`  // usernames which can be registered by master key only
+}
+
+type GenesisBalance struct {
+	Address crypto.Address `json:"address"` //
+	Asset   assets.Asset   `json:"asset"`   // by default: assets.Default
+	Amount  bignum.Int     `json:"amount"`  //
+}
+
+// LoadGenesis reads genesis from json-file
+func LoadGenesis(filename string) (*Genesis, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGenesis(data)
+}
+
+// ParseGenesis decodes and verifies genesis from json
+func ParseGenesis(data []byte) (g *Genesis, err error) {
+	if err = json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	for _, b := range g.Balances {
+		if b != nil && b.Asset.Empty() {
+			b.Asset = assets.Default
+		}
+	}
+	if err = g.Verify(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (g *Genesis) Verify() error {
+	if g.MasterKey.Empty() || g.EmissionKey.Empty() {
+		return ErrGenesisEmptyKey
+	}
+	if err := g.Schedule.Verify(); err != nil {
+		return err
+	}
+	balances := map[string]bool{}
+	for _, b := range g.Balances {
+		if b == nil || b.Address.Empty() || b.Asset.Empty() || b.Amount.Sign() <= 0 {
+			return ErrGenesisIncorrectBalance
+		}
+		key := string(b.Asset) + string(b.Address[:])
+		if balances[key] {
+			return ErrGenesisDuplicateBalance
+		}
+		balances[key] = true
+	}
+	for _, nick := range g.Usernames {
+		if !assets.IsValidName(nick) {
+			return ErrGenesisIncorrectUsername
+		}
+	}
+	return nil
+}
+
+// Apply sets network params of genesis to the node configuration and returns master and emission keys of genesis.
+// Storage of node assigns the keys to config.MasterPublicKey and config.EmissionPublicKey
+func (g *Genesis) Apply(cfg *Config) (masterKey, emissionKey *crypto.PublicKey) {
+	cfg.Genesis = g
+	cfg.NetworkID = g.Network
+	cfg.ChainID = g.ChainID
+	cfg.Consensus = g.Consensus
+	cfg.PoSRate = g.PoSRate
+	cfg.EmissionSchedule = g.Schedule
+	cfg.EmissionDeltaFrom = g.DeltaFrom
+	if g.SlotTimeout > 0 {
+		cfg.SlotTimeout = time.Duration(g.SlotTimeout) * time.Second
+	}
+	if g.PoSMinAge > 0 {
+		cfg.PoSMinStakeAge = time.Duration(g.PoSMinAge) * time.Second
+	}
+	return g.MasterKey, g.EmissionKey
+}
+
+// State returns initial state values (balances and validator set)
+func (g *Genesis) State() (vv state.Values) {
+	for _, b := range g.Balances {
+		vv = append(vv, &state.Value{
+			ChainID: g.ChainID,
+			Asset:   b.Asset,
+			Address: b.Address,
+			Balance: b.Amount,
+		})
+	}
+	for _, addr := range g.Validators {
+		vv = append(vv, &state.Value{
+			ChainID: g.ChainID,
+			Asset:   assets.Validators,
+			Address: addr,
+			Balance: bignum.NewInt(1),
+		})
+	}
+	return
+}
+
+// Header returns header of genesis block. StateRoot of the header is the root of initial state
+func (g *Genesis) Header() *BlockHeader {
+	stTree := patricia.NewTree(patricia.NewMemoryStorage(nil))
+	for _, v := range g.State() {
+		stTree.Put(v.StateKey(), v.Balance.Bytes())
+	}
+	stateRoot, _ := stTree.Root()
+
+	return &BlockHeader{
+		Version:   0,
+		Num:       0,
+		ChainID:   g.ChainID,
+		Network:   g.Network,
+		Timestamp: g.Timestamp,
+		StateRoot: stateRoot,
+		Miner:     g.MasterKey,
+	}
+}
 
 func GenesisBlockHeader(cfg *Config) *BlockHeader {
+	if cfg.Genesis != nil {
+		return cfg.Genesis.Header()
+	}
 	return &BlockHeader{
 		Version:   0,
 		Num:       0,
@@ -0,0 +1,84 @@
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	genesisMaster   = crypto.NewPrivateKeyBySecret("genesis master")
+	genesisEmission = crypto.NewPrivateKeyBySecret("genesis emission")
+	genesisHolder   = crypto.NewPrivateKeyBySecret("genesis holder").PublicKey.Address()
+)
+
+func testGenesisJSON(amount string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"network": 1,
+		"chain": 7,
+		"timestamp": 1500000000000000,
+		"emission_delta_from": 100,
+		"master_key": "%s",
+		"emission_key": "%s",
+		"balances": [{"address": "%s", "amount": %s}],
+		"reserved_usernames": ["admin", "support"]
+	}`, genesisMaster.PublicKey, genesisEmission.PublicKey, genesisHolder, amount))
+}
+
+func TestParseGenesis(t *testing.T) {
+	g, err := ParseGenesis(testGenesisJSON("1000"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), g.ChainID)
+	assert.True(t, g.MasterKey.Equal(genesisMaster.PublicKey))
+	assert.Equal(t, assets.Default, g.Balances[0].Asset)
+	assert.Equal(t, int64(1000), g.Balances[0].Amount.Int64())
+	assert.Equal(t, []string{"admin", "support"}, g.Usernames)
+}
+
+func TestParseGenesis_failByBalance(t *testing.T) {
+	_, err := ParseGenesis(testGenesisJSON("0"))
+
+	assert.Equal(t, ErrGenesisIncorrectBalance, err)
+}
+
+func TestParseGenesis_failByUsername(t *testing.T) {
+	g, _ := ParseGenesis(testGenesisJSON("1000"))
+
+	for _, nick := range []string{"ad", "admin!", "1admin", "ad min"} {
+		g.Usernames = []string{nick}
+		assert.Equal(t, ErrGenesisIncorrectUsername, g.Verify())
+	}
+}
+
+func TestGenesis_Header(t *testing.T) {
+	g1, _ := ParseGenesis(testGenesisJSON("1000"))
+	g2, _ := ParseGenesis(testGenesisJSON("1001"))
+
+	h1, h2 := g1.Header(), g2.Header()
+
+	assert.Equal(t, uint64(7), h1.ChainID)
+	assert.Equal(t, int64(1500000000000000), h1.Timestamp)
+	assert.True(t, bytes.Equal(h1.Hash(), g1.Header().Hash()))
+	assert.False(t, bytes.Equal(h1.StateRoot, h2.StateRoot))
+}
+
+func TestGenesis_Apply(t *testing.T) {
+	g, _ := ParseGenesis(testGenesisJSON("1000"))
+	cfg := &Config{}
+	emission0 := config.EmissionPublicKey
+
+	_, emissionKey := g.Apply(cfg)
+
+	assert.Equal(t, uint64(7), cfg.ChainID)
+	assert.Equal(t, NetworkTest, cfg.NetworkID)
+	assert.Equal(t, uint64(100), cfg.EmissionDeltaFrom)
+	assert.True(t, emissionKey.Equal(genesisEmission.PublicKey))
+	assert.True(t, config.EmissionPublicKey == emission0) // global keys are not changed
+	assert.Equal(t, g.Header().Hash(), GenesisBlockHeader(cfg).Hash())
+}
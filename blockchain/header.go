@@ -3,6 +3,7 @@ package blockchain
 import (
 	"bytes"
 	"fmt"
+	"time"
 
 	"github.com/denisskin/bin"
 	"github.com/likecoin-pro/likecoin/config"
@@ -115,6 +116,10 @@ func (b *BlockHeader) VerifyHeader(pre *BlockHeader, cfg *Config) error {
 	if b.Num == 0 && bytes.Equal(blockHash, GenesisBlockHeader(cfg).Hash()) { // is genesis
 		return ErrInvalidGenesisBlock
 	}
+	// timestamp defines the slot of proof-of-authority leader; miner can not take the future slots
+	if b.Timestamp > Timestamp()+int64(maxBlockTimeDrift/time.Microsecond) {
+		return ErrBlockFromFuture
+	}
 	if pre != nil {
 		if b.Network != pre.Network {
 			return ErrInvalidNetwork
@@ -132,12 +137,28 @@ func (b *BlockHeader) VerifyHeader(pre *BlockHeader, cfg *Config) error {
 			return ErrInvalidPrevHash
 		}
 	}
+	if cfg.Consensus == ConsensusPoA {
+		// authority of miner is verified by validator set of state (see VerifyMiner)
+		return b.verifyMinerSig()
+	}
+	return b.VerifySig()
+}
+
+// VerifySig verifies miner key and miner signature of block header
+func (b *BlockHeader) VerifySig() error {
 	if b.Miner.Empty() {
 		return ErrEmptyMinerKey
 	}
 	if !b.Miner.Equal(config.MasterPublicKey) {
 		return ErrInvalidMinerKey
 	}
+	return b.verifyMinerSig()
+}
+
+func (b *BlockHeader) verifyMinerSig() error {
+	if b.Miner.Empty() {
+		return ErrEmptyMinerKey
+	}
 	if !b.Miner.Verify(b.sigHash(), b.Sig) {
 		return ErrInvalidBlockSig
 	}
@@ -0,0 +1,23 @@
+package blockchain
+
+import (
+	"time"
+
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+)
+
+// usecPerYear is the length of year for proof-of-stake rate
+const usecPerYear = 365 * 24 * 3600 * 1e6
+
+// PoSReward returns reward of proof-of-stake minting for stake which has not been changed from time `since` till time `at` (in µsec).
+// Reward is proportional to the annual rate and the stake age; it is 0 if stake age is less than cfg.PoSMinStakeAge.
+func PoSReward(cfg *Config, stake bignum.Int, since, at int64) bignum.Int {
+	age := at - since
+	if cfg.PoSRate <= 0 || stake.Sign() <= 0 || age <= 0 || age < int64(cfg.PoSMinStakeAge/time.Microsecond) {
+		return bignum.Int{}
+	}
+	return stake.
+		Mul(bignum.NewInt(cfg.PoSRate)).
+		Mul(bignum.NewInt(age)).
+		Div(bignum.NewInt(1e4 * usecPerYear))
+}
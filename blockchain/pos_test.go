@@ -0,0 +1,36 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/stretchr/testify/assert"
+)
+
+var posCfg = &Config{
+	PoSRate:        500, // 5%
+	PoSMinStakeAge: 24 * time.Hour,
+}
+
+func TestPoSReward(t *testing.T) {
+	const year = int64(365 * 24 * time.Hour / time.Microsecond)
+	stake := bignum.NewInt(1e12)
+
+	assert.Equal(t, int64(5e10), PoSReward(posCfg, stake, 1e15, 1e15+year).Int64())
+	assert.Equal(t, int64(2.5e10), PoSReward(posCfg, stake, 1e15, 1e15+year/2).Int64())
+}
+
+func TestPoSReward_minStakeAge(t *testing.T) {
+	day := int64(24 * time.Hour / time.Microsecond)
+	stake := bignum.NewInt(1e12)
+
+	assert.Equal(t, int64(0), PoSReward(posCfg, stake, 1e15, 1e15+day-1).Int64())
+	assert.True(t, PoSReward(posCfg, stake, 1e15, 1e15+day).Sign() > 0)
+}
+
+func TestPoSReward_off(t *testing.T) {
+	cfg := &Config{PoSRate: 0}
+
+	assert.Equal(t, int64(0), PoSReward(cfg, bignum.NewInt(1e12), 0, 1e15).Int64())
+}
@@ -0,0 +1,42 @@
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+)
+
+var ErrInvalidEmissionSchedule = errors.New("invalid emission schedule")
+
+// EmissionPeriod is the period of published schedule of emission
+type EmissionPeriod struct {
+	From        int64      `json:"from"`          // start of period (block timestamp in µsec)
+	Rate        bignum.Int `json:"rate"`          // like rate in nano-coins
+	MaxPerBlock bignum.Int `json:"max_per_block"` // max amount of all emissions by block in nano-coins (0 - unlimited)
+}
+
+// EmissionSchedule is the list of periods ordered by start time
+type EmissionSchedule []*EmissionPeriod
+
+func (s EmissionSchedule) Verify() error {
+	for i, p := range s {
+		if p == nil || p.Rate.Sign() <= 0 || p.MaxPerBlock.Sign() < 0 {
+			return ErrInvalidEmissionSchedule
+		}
+		if i > 0 && p.From <= s[i-1].From {
+			return ErrInvalidEmissionSchedule
+		}
+	}
+	return nil
+}
+
+// PeriodAt returns period of schedule at the time ts (nil - time is before the schedule)
+func (s EmissionSchedule) PeriodAt(ts int64) (period *EmissionPeriod) {
+	for _, p := range s {
+		if p.From > ts {
+			break
+		}
+		period = p
+	}
+	return
+}
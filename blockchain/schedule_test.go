@@ -0,0 +1,28 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSchedule = EmissionSchedule{
+	{From: 100, Rate: bignum.NewInt(10)},
+	{From: 200, Rate: bignum.NewInt(5), MaxPerBlock: bignum.NewInt(1000)},
+}
+
+func TestEmissionSchedule_PeriodAt(t *testing.T) {
+	assert.Nil(t, testSchedule.PeriodAt(99))
+	assert.Equal(t, int64(10), testSchedule.PeriodAt(100).Rate.Int64())
+	assert.Equal(t, int64(10), testSchedule.PeriodAt(199).Rate.Int64())
+	assert.Equal(t, int64(5), testSchedule.PeriodAt(1e15).Rate.Int64())
+}
+
+func TestEmissionSchedule_Verify(t *testing.T) {
+	assert.NoError(t, testSchedule.Verify())
+	assert.NoError(t, EmissionSchedule(nil).Verify())
+
+	assert.Equal(t, ErrInvalidEmissionSchedule, EmissionSchedule{testSchedule[1], testSchedule[0]}.Verify())
+	assert.Equal(t, ErrInvalidEmissionSchedule, EmissionSchedule{{From: 100}}.Verify())
+}
@@ -0,0 +1,60 @@
+package blockchain
+
+import (
+	"runtime"
+	"sync"
+)
+
+// VerifyTxs verifies transactions (sender signatures and tx-objects) by pool of goroutines.
+// Returns the first error of verification.
+func VerifyTxs(cfg *Config, txs []*Transaction) error {
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	if workers <= 1 {
+		for _, tx := range txs {
+			if err := tx.Verify(cfg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mx     sync.Mutex
+		resErr error
+		next   = make(chan *Transaction, len(txs))
+	)
+	for _, tx := range txs {
+		next <- tx
+	}
+	close(next)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tx := range next {
+				if err := tx.Verify(cfg); err != nil {
+					mx.Lock()
+					if resErr == nil {
+						resErr = err
+					}
+					mx.Unlock()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return resErr
+}
+
+// BlocksTxs returns all transactions of blocks
+func BlocksTxs(blocks []*Block) (txs []*Transaction) {
+	for _, b := range blocks {
+		txs = append(txs, b.Txs...)
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package blockchain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testVerifyCfg = &Config{
+	NetworkID: NetworkTest,
+	ChainID:   1,
+}
+
+func newTestTxs(n int) (txs []*Transaction) {
+	for i := 0; i < n; i++ {
+		txs = append(txs, NewTx(testVerifyCfg, testPrv, uint64(i+1), &TestTxObject{Msg: fmt.Sprintf("msg-%d", i)}))
+	}
+	return
+}
+
+func TestVerifyTxs(t *testing.T) {
+	txs := newTestTxs(100)
+
+	err := VerifyTxs(testVerifyCfg, txs)
+
+	assert.NoError(t, err)
+}
+
+func TestVerifyTxs_fail(t *testing.T) {
+	txs := newTestTxs(100)
+	txs[77].Sig[3]++ // corrupt sign
+
+	err := VerifyTxs(testVerifyCfg, txs)
+
+	assert.Equal(t, ErrInvalidBlockSig, err)
+}
+
+func TestVerifyTxs_failByObject(t *testing.T) {
+	txs := newTestTxs(10)
+	txs = append(txs, NewTx(testVerifyCfg, testPrv, 0, &TestTxObject{Msg: string(make([]byte, 101))}))
+
+	err := VerifyTxs(testVerifyCfg, txs)
+
+	assert.Error(t, err)
+}
+
+// BenchmarkVerifyTxs_serial verifies txs one by one (as PutBlock did before)
+func BenchmarkVerifyTxs_serial(b *testing.B) {
+	txs := newTestTxs(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, tx := range txs {
+			if err := tx.Verify(testVerifyCfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkVerifyTxs_parallel(b *testing.B) {
+	txs := newTestTxs(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := VerifyTxs(testVerifyCfg, txs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/blockchain/chainfile"
+	"github.com/likecoin-pro/likecoin/blockchain/db"
+	"github.com/likecoin-pro/likecoin/commons/log"
+)
+
+type command func(bc *db.BlockchainStorage, args []string) error
+
+var commands = map[string]command{
+	"init":     cmdInit,
+	"reindex":  cmdReindex,
+	"export":   cmdExport,
+	"import":   cmdImport,
+	"snapshot": cmdSnapshot,
+	"restore":  cmdRestore,
+}
+
+const importBatchSize = 100 // blocks in one PutBlock call
+
+func execCommand(bc *db.BlockchainStorage, name string, args []string) {
+	defer bc.Close()
+
+	fn, ok := commands[name]
+	if !ok {
+		log.Fatal.Printf("likecd: unknown command %q", name)
+		os.Exit(2)
+	}
+	if err := fn(bc, args); err != nil {
+		log.Fatal.Printf("likecd %s: %v", name, err)
+		bc.Close()
+		os.Exit(1)
+	}
+}
+
+// likecd init -genesis=<file>
+func cmdInit(bc *db.BlockchainStorage, args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	filename := fs.String("genesis", "genesis.json", "Genesis file")
+	fs.Parse(args)
+
+	g, err := blockchain.LoadGenesis(*filename)
+	if err != nil {
+		return err
+	}
+	if err = bc.InitGenesis(g); err != nil {
+		return err
+	}
+	log.Printf("init> genesis block of chain#%d (network %d) is written to db. hash: %x", g.ChainID, g.Network, blockchain.GenesisBlockHeader(bc.Cfg).Hash())
+	return nil
+}
+
+// likecd reindex
+func cmdReindex(bc *db.BlockchainStorage, args []string) error {
+	return bc.Reindex()
+}
+
+// likecd export [-from=<blockNum>] [-to=<blockNum>] -out=<file>
+func cmdExport(bc *db.BlockchainStorage, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	from := fs.Uint64("from", 1, "First block num")
+	to := fs.Uint64("to", 0, "Last block num (by default: last block)")
+	out := fs.String("out", "chain.bin", "Output file")
+	fs.Parse(args)
+
+	if *to == 0 {
+		*to = bc.LastBlock().Num
+	}
+	if *from == 0 || *from > *to {
+		return errors.New("invalid blocks range")
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := chainfile.NewWriter(f, bc.Cfg.NetworkID, bc.Cfg.ChainID)
+	if err != nil {
+		return err
+	}
+	err = bc.FetchBlocks(*from-1, int64(*to-*from+1), false, func(block *blockchain.Block) error {
+		if block.Num%10000 == 0 {
+			log.Printf("export> block#%d", block.Num)
+		}
+		return w.WriteBlock(block)
+	})
+	if err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	hdr := w.Header()
+	log.Printf("export> %d blocks (#%d - #%d) are exported to %s", hdr.Count, hdr.From, hdr.To, *out)
+	return nil
+}
+
+// likecd import <file>
+func cmdImport(bc *db.BlockchainStorage, args []string) error {
+	if len(args) == 0 {
+		return errors.New("file is not specified")
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// verify checksum of file
+	r, err := chainfile.NewReader(f)
+	if err != nil {
+		return err
+	}
+	hdr := r.Header()
+	if hdr.Network != bc.Cfg.NetworkID || hdr.ChainID != bc.Cfg.ChainID {
+		return chainfile.ErrInvalidChainID
+	}
+	if lastNum := bc.LastBlock().Num; hdr.From > lastNum+1 {
+		return errors.New("file does not continue the local chain")
+	}
+	if err = r.Verify(); err != nil {
+		return err
+	}
+
+	// put blocks
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if r, err = chainfile.NewReader(f); err != nil {
+		return err
+	}
+	var batch []*blockchain.Block
+	putBatch := func() error {
+		if err := bc.PutBlock(batch...); err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			log.Printf("import> ✅ imported block#%d", batch[len(batch)-1].Num)
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		block, err := r.ReadBlock()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if block.Num <= bc.LastBlock().Num { // skip existing blocks
+			continue
+		}
+		if batch = append(batch, block); len(batch) >= importBatchSize {
+			if err = putBatch(); err != nil {
+				return err
+			}
+		}
+	}
+	return putBatch()
+}
+
+// likecd snapshot -out=<file>
+func cmdSnapshot(bc *db.BlockchainStorage, args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	out := fs.String("out", "state.snap", "Output file")
+	fs.Parse(args)
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err = bc.WriteSnapshot(w); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	log.Printf("snapshot> state of block#%d is saved to %s", bc.LastBlock().Num, *out)
+	return nil
+}
+
+// likecd restore <file>
+func cmdRestore(bc *db.BlockchainStorage, args []string) error {
+	if len(args) == 0 {
+		return errors.New("file is not specified")
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return bc.LoadSnapshot(bufio.NewReader(f))
+}
@@ -1,9 +1,17 @@
 package main
 
 import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/likecoin-pro/likecoin/blockchain"
 	"github.com/likecoin-pro/likecoin/blockchain/db"
+	"github.com/likecoin-pro/likecoin/commons/log"
 	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/services/devnet"
+	"github.com/likecoin-pro/likecoin/services/p2p"
 	"github.com/likecoin-pro/likecoin/services/replication"
 	"github.com/likecoin-pro/likecoin/services/webapi"
 )
@@ -12,16 +20,75 @@ func main() {
 	// config
 	apiCfg := webapi.NewConfig()
 	bcCfg := blockchain.NewConfig()
+	p2pCfg := p2p.NewConfig()
+	replCfg := replication.NewConfig()
+	devCfg := devnet.NewConfig()
 	config.ParseArgs()
 
+	// local devnet mode
+	if devCfg.Enabled {
+		startDevnet(apiCfg, bcCfg)
+		return
+	}
+
 	// init blockchain
 	bc := db.NewBlockchainStorage(bcCfg)
 
+	// execute command:  likecd [flags] <command> [args]
+	if cmd := flag.Arg(0); cmd != "" {
+		execCommand(bc, cmd, flag.Args()[1:])
+		return
+	}
+
+	// indexes are incomplete after interrupted reindex
+	if num, ok, err := bc.ReindexProgress(); err != nil {
+		log.Panic(err)
+	} else if ok {
+		log.Fatal.Printf("likecd: reindex has been interrupted at block#%d; run `likecd reindex` to continue", num)
+		os.Exit(1)
+	}
+
 	// start web-server
 	go webapi.StartServer(apiCfg, bc)
 
+	// start p2p-node
+	if p2pCfg.ListenAddr != "" || len(p2pCfg.Seeds) > 0 {
+		node := p2p.NewNode(p2pCfg, bcCfg, bc, bc.Mempool)
+		bc.AddCommitHandler(node.AnnounceBlocks)
+		bc.Mempool.AddPutHandler(node.AnnounceTxs)
+		if err := node.Start(); err != nil {
+			log.Panic(err)
+		}
+	}
+
 	// start blockchain-replication
-	go replication.NewService(nil, bc).StartReplication()
+	go replication.NewService(replCfg, bc).StartReplication()
 
 	select {}
 }
+
+// startDevnet starts node of local devnet (without replication and p2p)
+func startDevnet(apiCfg *webapi.Config, bcCfg *blockchain.Config) {
+	dev, err := devnet.New(bcCfg)
+	if err != nil {
+		log.Panic(err)
+	}
+	if err = dev.Start(db.NewBlockchainStorage(bcCfg)); err != nil {
+		dev.Close()
+		log.Panic(err)
+	}
+
+	// remove temporary db on shutdown
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		if err := dev.Close(); err != nil {
+			log.Error.Printf("devnet> Close Error: %v", err)
+		}
+		os.Exit(0)
+	}()
+
+	apiCfg.Faucet = dev.Faucet
+	webapi.StartServer(apiCfg, dev.Storage())
+}
@@ -130,6 +130,21 @@ func (t *Tree) AppendingProof(newKey, value []byte) (proof, root []byte, err err
 	return
 }
 
+// Fetch calls fn for each key-value of the tree.
+// Integrity of tree-nodes is verified by hashes of child nodes, so the tree with valid Root contains original values.
+func (t *Tree) Fetch(fn func(key, value []byte) error) error {
+	root, err := t.fetch(make([]byte, 0, 10), fn)
+	if err != nil {
+		return err
+	}
+	if r, err := t.Root(); err != nil {
+		return err
+	} else if !bytes.Equal(r, root) {
+		return errInvalidNodeData
+	}
+	return nil
+}
+
 //--------------------------------------------------
 func (t *Tree) getNode(key []byte) (nd *node, err error) {
 	if t.puts != nil {
@@ -209,3 +224,31 @@ func (t *Tree) proof(path, key []byte) (value, proof []byte, err error) {
 	proof = append(proof, nd.proof(int(i))...)
 	return
 }
+
+func (t *Tree) fetch(path []byte, fn func(key, value []byte) error) (hash []byte, err error) {
+	nd, err := t.getNode(path)
+	if err != nil || nd == nil {
+		return
+	}
+	if nd.key != nil { // leaf
+		for lv, i := range path { // leaf have to be placed by key-path
+			if lv >= len(nd.key)*2 || idx(nd.key, lv) != i {
+				return nil, errInvalidNodeData
+			}
+		}
+		return nd.hash(), fn(nd.key, nd.value)
+	}
+	for i, h := range nd.hashes {
+		if h == nil {
+			continue
+		}
+		subHash, err := t.fetch(append(path, uint8(i)), fn)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(subHash, h) {
+			return nil, errInvalidNodeData
+		}
+	}
+	return nd.hash(), nil
+}
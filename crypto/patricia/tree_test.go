@@ -187,6 +187,45 @@ func TestTree_AppendingProof(t *testing.T) {
 	}
 }
 
+func TestTree_Fetch(t *testing.T) {
+	a := NewTree(nil)
+	b := NewTree(nil)
+	for k, v := range testValues(5000) {
+		a.PutVar(k, v)
+	}
+
+	var n int
+	err := a.Fetch(func(key, value []byte) error {
+		n++
+		return b.Put(key, value)
+	})
+
+	aRoot, _ := a.Root()
+	bRoot, _ := b.Root()
+	assert.NoError(t, err)
+	assert.Equal(t, 5000, n)
+	assert.Equal(t, aRoot, bRoot)
+}
+
+func TestTree_Fetch_fail(t *testing.T) {
+	db := NewMemoryStorage(nil)
+	a := NewTree(db)
+	for k, v := range testValues(100) {
+		a.PutVar(k, v)
+	}
+	for path, data := range db.data { // corrupt value of some leaf
+		if nd := new(node); nd.decode(data) == nil && nd.key != nil {
+			nd.value = []byte("corrupted")
+			db.data[path] = nd.encode()
+			break
+		}
+	}
+
+	err := a.Fetch(func(key, value []byte) error { return nil })
+
+	assert.Error(t, err)
+}
+
 func testValues(n int) map[int][]byte {
 	v := make(map[int][]byte, n)
 	for i := 0; i < n; i++ {
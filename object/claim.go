@@ -0,0 +1,115 @@
+package object
+
+import (
+	"github.com/denisskin/bin"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/blockchain/state"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/crypto/merkle"
+)
+
+// Claim delivers cross-chain outs of source-chain transaction to the destination chain.
+// Source transaction is proved by signed header of source block and merkle proof of tx.TxStHash() in header.TxRoot.
+type Claim struct {
+	Object
+	Header *blockchain.BlockHeader `json:"header"` // header of source-chain block
+	SrcTx  *blockchain.Transaction `json:"tx"`     // source-chain transaction (with state updates)
+	Proof  bin.Bytes               `json:"proof"`  // merkle proof of SrcTx.TxStHash() in Header.TxRoot
+}
+
+var _ = blockchain.RegisterTxObject(TxTypeClaim, &Claim{})
+
+func NewClaim(
+	cfg *blockchain.Config,
+	from *crypto.PrivateKey,
+	header *blockchain.BlockHeader,
+	srcTx *blockchain.Transaction,
+	proof []byte,
+) *blockchain.Transaction {
+	return blockchain.NewTx(cfg, from, 0, &Claim{
+		Header: header,
+		SrcTx:  srcTx,
+		Proof:  proof,
+	})
+}
+
+func (obj *Claim) Encode() []byte {
+	return bin.Encode(
+		0, // ver
+		obj.Header,
+		obj.SrcTx,
+		obj.Proof,
+	)
+}
+
+func (obj *Claim) Decode(data []byte) error {
+	return bin.Decode(data,
+		new(int),
+		&obj.Header,
+		&obj.SrcTx,
+		&obj.Proof,
+	)
+}
+
+// ClaimAsset returns marker-asset of claimed source transaction
+func (obj *Claim) ClaimAsset() assets.Asset {
+	return assets.NewClaim(obj.SrcTx.ChainID, obj.SrcTx.Hash())
+}
+
+// Outs returns state updates of source transaction addressed to the destination chain.
+// Outs of non-transferable assets are rejected by Verify and Execute
+func (obj *Claim) Outs() (outs state.Values) {
+	chainID := obj.Tx().ChainID
+	for _, v := range obj.SrcTx.StateUpdates {
+		if v.ChainID == chainID && v.Balance.Sign() > 0 {
+			outs = append(outs, v)
+		}
+	}
+	return
+}
+
+func (obj *Claim) Verify() error {
+	tx := obj.Tx()
+	if obj.Header == nil || obj.SrcTx == nil {
+		return ErrTxIncorrectSrcProof
+	}
+	if obj.Header.ChainID == tx.ChainID || obj.Header.Network != tx.Network {
+		return ErrTxIncorrectSrcChain
+	}
+	if obj.SrcTx.ChainID != obj.Header.ChainID || obj.SrcTx.Network != obj.Header.Network {
+		return ErrTxIncorrectSrcChain
+	}
+	for _, v := range obj.Outs() {
+		if !v.Asset.IsTransferable() {
+			return ErrTxIncorrectAssetType
+		}
+	}
+	if err := obj.Header.VerifySig(); err != nil {
+		return err
+	}
+	if !merkle.Verify(obj.SrcTx.TxStHash(), obj.Proof, obj.Header.TxRoot) {
+		return ErrTxIncorrectSrcProof
+	}
+	if len(obj.Outs()) == 0 {
+		return ErrTxEmptyCrossChainOuts
+	}
+	return nil
+}
+
+func (obj *Claim) Execute(st *state.State) {
+	// source transaction can be claimed only once
+	marker := obj.ClaimAsset()
+	if st.Get(marker, crypto.NilAddress).Sign() > 0 {
+		st.Fail(ErrTxAlreadyClaimed)
+	}
+	st.Increment(marker, crypto.NilAddress, bignum.NewInt(1), 0)
+
+	for _, v := range obj.Outs() {
+		if !v.Asset.IsTransferable() {
+			st.Fail(ErrTxIncorrectAssetType)
+		}
+		st.Increment(v.Asset, v.Address, v.Balance, v.Memo)
+	}
+}
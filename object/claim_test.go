@@ -0,0 +1,106 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/blockchain/state"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/crypto/merkle"
+	"github.com/stretchr/testify/assert"
+)
+
+var dstCfg = &blockchain.Config{
+	NetworkID: blockchain.NetworkTest,
+	ChainID:   2,
+}
+
+// newTestClaim makes transfer Alice->Bob from chain 1 to chain 2 and returns claim-tx on chain 2
+func newTestClaim() *blockchain.Transaction {
+	srcTx := NewSimpleTransfer(testCfg, aliceKey, bobAddr, bignum.NewInt(100), coin, "", 0, 0)
+	srcTx.TxObject().(*Transfer).Outs[0].ToChainID = dstCfg.ChainID
+	st := state.NewState(testCfg.ChainID, func(assets.Asset, crypto.Address) bignum.Int {
+		return bignum.NewInt(1000)
+	})
+	srcTx.StateUpdates, _ = srcTx.Execute(st)
+
+	otherTx := NewSimpleTransfer(testCfg, bobKey, aliceAddr, bignum.NewInt(1), coin, "", 0, 0)
+	proof, root := merkle.Proof([][]byte{srcTx.TxStHash(), otherTx.TxStHash()}, 0)
+
+	header := &blockchain.BlockHeader{
+		Network: testCfg.NetworkID,
+		ChainID: testCfg.ChainID,
+		Num:     1,
+		TxRoot:  root,
+		Miner:   masterKey.PublicKey,
+	}
+	header.Sig = masterKey.Sign(merkle.Root(header.Hash(), header.ChainRoot))
+
+	return NewClaim(dstCfg, bobKey, header, srcTx, proof)
+}
+
+func TestClaim_Verify(t *testing.T) {
+	tx := newTestClaim()
+
+	err := tx.Verify(dstCfg)
+
+	assert.NoError(t, err)
+}
+
+func TestClaim_Verify_failBySig(t *testing.T) {
+	tx := newTestClaim()
+	claim := tx.TxObject().(*Claim)
+
+	claim.Header.Sig[3]++ // corrupt sign of source block
+
+	err := claim.Verify()
+
+	assert.Equal(t, blockchain.ErrInvalidBlockSig, err)
+}
+
+func TestClaim_Verify_failByProof(t *testing.T) {
+	tx := newTestClaim()
+	claim := tx.TxObject().(*Claim)
+
+	claim.Proof[5]++ // corrupt proof
+
+	err := claim.Verify()
+
+	assert.Equal(t, ErrTxIncorrectSrcProof, err)
+}
+
+func TestClaim_Verify_failByAsset(t *testing.T) {
+	tx := newTestClaim()
+	claim := tx.TxObject().(*Claim)
+
+	claim.Outs()[0].Asset = assets.Validators // out of validator seat to the destination chain
+
+	err := claim.Verify()
+
+	assert.Equal(t, ErrTxIncorrectAssetType, err)
+}
+
+func TestClaim_Execute(t *testing.T) {
+	tx := newTestClaim()
+	st := state.NewState(dstCfg.ChainID, nil)
+
+	upd, err := tx.Execute(st)
+
+	assert.NoError(t, err)
+	st.Apply(upd)
+	assert.Equal(t, int64(100), st.Get(coin, bobAddr).Int64())
+}
+
+func TestClaim_Execute_failTwice(t *testing.T) {
+	tx := newTestClaim()
+	st := state.NewState(dstCfg.ChainID, nil)
+	upd, _ := tx.Execute(st)
+	st.Apply(upd)
+
+	_, err := newTestClaim().Execute(st)
+
+	assert.Error(t, err)
+	assert.Equal(t, int64(100), st.Get(coin, bobAddr).Int64())
+}
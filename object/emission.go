@@ -32,6 +32,7 @@ var _ = blockchain.RegisterTxObject(TxTypeEmission, &Emission{})
 const (
 	ReferralRewardTxComment = "referral_reward"
 	PoSMintingTxComment     = "pos_minting"
+	FaucetTxComment         = "faucet" // emission of devnet faucet
 )
 
 var (
@@ -57,6 +58,16 @@ func NewEmission(
 	})
 }
 
+// NewPoSMinting returns proof-of-stake minting emission; Delta of out is the reward in nano-coins
+func NewPoSMinting(
+	cfg *blockchain.Config,
+	emissionKey *crypto.PrivateKey,
+	asset assets.Asset,
+	vv []*EmissionOut,
+) *blockchain.Transaction {
+	return NewEmission(cfg, emissionKey, asset, bignum.NewInt(1), PoSMintingTxComment, vv)
+}
+
 func (obj *Emission) Encode() []byte {
 	return bin.Encode(
 		0, // ver
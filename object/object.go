@@ -8,9 +8,11 @@ import (
 )
 
 const (
-	TxTypeEmission = 0
-	TxTypeTransfer = 1
-	TxTypeUser     = 2
+	TxTypeEmission  = 0
+	TxTypeTransfer  = 1
+	TxTypeUser      = 2
+	TxTypeClaim     = 3
+	TxTypeValidator = 4
 )
 
 var (
@@ -20,6 +22,13 @@ var (
 	ErrTxIncorrectOutAddress = errors.New("tx-Error: Incorrect out address")
 
 	ErrInvalidUserID = errors.New("invalid userID")
+
+	ErrTxIncorrectSrcChain   = errors.New("tx-Error: Incorrect source chain")
+	ErrTxIncorrectSrcProof   = errors.New("tx-Error: Incorrect proof of source transaction")
+	ErrTxEmptyCrossChainOuts = errors.New("tx-Error: Source transaction has no outs to the chain")
+	ErrTxAlreadyClaimed      = errors.New("tx-Error: Source transaction has been already claimed")
+
+	ErrTxIncorrectValidator = errors.New("tx-Error: Incorrect validator address")
 )
 
 type Object struct {
@@ -10,6 +10,7 @@ import (
 var (
 	coin = assets.Likecoin
 
+	masterKey   = crypto.NewPrivateKeyBySecret("Test master key")
 	emissionKey = crypto.NewPrivateKeyBySecret("Test master key")
 	aliceKey    = crypto.NewPrivateKeyBySecret("alice::Alice secret")
 	bobKey      = crypto.NewPrivateKeyBySecret("bob::Bob secret")
@@ -25,5 +26,6 @@ var (
 )
 
 func init() {
+	config.MasterPublicKey = masterKey.PublicKey
 	config.EmissionPublicKey = emissionKey.PublicKey
 }
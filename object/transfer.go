@@ -113,6 +113,9 @@ func (obj *Transfer) Totals() map[string]bignum.Int {
 func (obj *Transfer) Verify() error {
 	sender := obj.SenderAddress()
 	for _, out := range obj.Outs {
+		if !out.Asset.IsTransferable() {
+			return ErrTxIncorrectAssetType
+		}
 		if out.To.Empty() || out.To.Equal(sender) {
 			return ErrTxIncorrectOutAddress
 		}
@@ -128,6 +131,11 @@ func (obj *Transfer) Execute(st *state.State) {
 	senderAddr := obj.SenderAddress()
 	for _, out := range obj.Outs {
 
+		// validator seats and markers of claims are changed by consensus only
+		if !out.Asset.IsTransferable() {
+			st.Fail(ErrTxIncorrectAssetType)
+		}
+
 		// decrement amount from address; panic if not enough funds
 		st.Decrement(out.Asset, senderAddr, out.Amount, out.Tag)
 
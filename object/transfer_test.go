@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain/state"
 	"github.com/likecoin-pro/likecoin/commons/bignum"
 	"github.com/likecoin-pro/likecoin/commons/enc"
+	"github.com/likecoin-pro/likecoin/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,6 +39,25 @@ func TestTransfer_Verify_fail(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestTransfer_Verify_failByAsset(t *testing.T) {
+	tx := NewSimpleTransfer(testCfg, aliceKey, bobAddr, bignum.NewInt(1), assets.Validators, "transfer of validator seat", 0, 0)
+
+	err := tx.Verify(testCfg)
+
+	assert.Equal(t, ErrTxIncorrectAssetType, err)
+}
+
+func TestTransfer_Execute_failByAsset(t *testing.T) {
+	tx := NewSimpleTransfer(testCfg, aliceKey, bobAddr, bignum.NewInt(1), assets.Validators, "", 0, 0)
+	st := state.NewState(testCfg.ChainID, func(assets.Asset, crypto.Address) bignum.Int {
+		return bignum.NewInt(1)
+	})
+
+	_, err := tx.Execute(st)
+
+	assert.Error(t, err)
+}
+
 func TestTransfer_JSONMarshal(t *testing.T) {
 	tx := NewSimpleTransfer(testCfg, aliceKey, bobAddr, bignum.NewInt(1.5e9), coin, "transfer to Bob", 123, 456)
 
@@ -2,7 +2,6 @@ package object
 
 import (
 	"errors"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -62,14 +61,12 @@ func (obj *User) Decode(data []byte) error {
 }
 
 var (
-	reNick = regexp.MustCompile(`^[a-z][a-z0-9\-]{2,20}$`)
-
 	errInvalidNickname   = errors.New("tx-user-verify: incorrect nickname")
 	errUserDataIsTooLong = errors.New("tx-user-verify: data is too long")
 )
 
 func (obj *User) Verify() error {
-	if !reNick.MatchString(obj.Nick) {
+	if !assets.IsValidName(obj.Nick) {
 		return errInvalidNickname
 	}
 	if len(obj.Data) > config.TxUserDataSizeLimit {
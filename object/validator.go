@@ -0,0 +1,67 @@
+package object
+
+import (
+	"github.com/denisskin/bin"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/blockchain/state"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/crypto"
+)
+
+// Validator is governance transaction which adds address to validator set of proof-of-authority consensus
+// or removes address from the set. The transaction must be signed by master key.
+type Validator struct {
+	Object
+	Address crypto.Address `json:"address"` // address of validator
+	Enabled bool           `json:"enabled"` // true - add to validator set; false - remove from validator set
+}
+
+var _ = blockchain.RegisterTxObject(TxTypeValidator, &Validator{})
+
+func NewValidator(
+	cfg *blockchain.Config,
+	from *crypto.PrivateKey,
+	addr crypto.Address,
+	enabled bool,
+) *blockchain.Transaction {
+	return blockchain.NewTx(cfg, from, 0, &Validator{
+		Address: addr,
+		Enabled: enabled,
+	})
+}
+
+func (obj *Validator) Encode() []byte {
+	return bin.Encode(
+		0, // ver
+		obj.Address,
+		obj.Enabled,
+	)
+}
+
+func (obj *Validator) Decode(data []byte) error {
+	return bin.Decode(data,
+		new(int),
+		&obj.Address,
+		&obj.Enabled,
+	)
+}
+
+func (obj *Validator) Verify() error {
+	if !obj.Sender().Equal(config.MasterPublicKey) { // Sender of governance-tx must be MasterPublicKey
+		return ErrTxIncorrectSender
+	}
+	if obj.Address.Empty() {
+		return ErrTxIncorrectValidator
+	}
+	return nil
+}
+
+func (obj *Validator) Execute(st *state.State) {
+	if obj.Enabled {
+		st.Set(assets.Validators, obj.Address, bignum.NewInt(1), 0)
+	} else {
+		st.Set(assets.Validators, obj.Address, bignum.NewInt(0), 0)
+	}
+}
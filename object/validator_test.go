@@ -0,0 +1,52 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/blockchain/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator_Decode(t *testing.T) {
+	data := NewValidator(testCfg, masterKey, aliceAddr, true).Encode()
+
+	var tx blockchain.Transaction
+	err := tx.Decode(data)
+
+	obj := tx.TxObject().(*Validator)
+	assert.NoError(t, err)
+	assert.Equal(t, aliceAddr, obj.Address)
+	assert.True(t, obj.Enabled)
+}
+
+func TestValidator_Verify(t *testing.T) {
+	tx := NewValidator(testCfg, masterKey, aliceAddr, true)
+
+	err := tx.Verify(testCfg)
+
+	assert.NoError(t, err)
+}
+
+func TestValidator_Verify_failBySender(t *testing.T) {
+	tx := NewValidator(testCfg, aliceKey, aliceAddr, true)
+
+	err := tx.Verify(testCfg)
+
+	assert.Equal(t, ErrTxIncorrectSender, err)
+}
+
+func TestValidator_Execute(t *testing.T) {
+	st := state.NewState(testCfg.ChainID, nil)
+
+	upd, err := NewValidator(testCfg, masterKey, aliceAddr, true).Execute(st)
+	assert.NoError(t, err)
+	st.Apply(upd)
+	assert.Equal(t, int64(1), st.Get(assets.Validators, aliceAddr).Int64())
+
+	upd, err = NewValidator(testCfg, masterKey, aliceAddr, false).Execute(st)
+	assert.NoError(t, err)
+	st.Apply(upd)
+	assert.Equal(t, int64(0), st.Get(assets.Validators, aliceAddr).Int64())
+}
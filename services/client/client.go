@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,12 +10,17 @@ import (
 
 	"github.com/denisskin/bin"
 	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/object"
 )
 
 type Client struct {
 	apiAddr string
 }
 
+func (c *Client) String() string {
+	return c.apiAddr
+}
+
 const DefaultAPIAddress = "http://likecoin.pro/api/v0"
 
 func NewClient(apiAddr string) *Client {
@@ -26,7 +32,23 @@ func NewClient(apiAddr string) *Client {
 	}
 }
 
+var errNotFound = errors.New("client: not found")
+
 func (c *Client) httpGet(path string, q url.Values, v interface{}, fn func()) (err error) {
+	err = c.httpStream(path, q, v, func() error {
+		if fn != nil {
+			fn()
+		}
+		return nil
+	})
+	if err == errNotFound {
+		return nil
+	}
+	return
+}
+
+// httpStream reads objects from response stream; fn is called for each object, reading is stopped if fn returns error
+func (c *Client) httpStream(path string, q url.Values, v interface{}, fn func() error) (err error) {
 	if q == nil {
 		q = url.Values{}
 	}
@@ -47,11 +69,14 @@ func (c *Client) httpGet(path string, q url.Values, v interface{}, fn func()) (e
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 404 {
-		return
+		return errNotFound
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("client.Get(%s)-Error: invalid response status code %d", path, resp.StatusCode)
 	}
 	for r := bin.NewReader(resp.Body); err == nil; {
-		if err = r.ReadVar(v); err == nil && fn != nil {
-			fn()
+		if err = r.ReadVar(v); err == nil {
+			err = fn()
 		}
 	}
 	if err == io.EOF {
@@ -84,6 +109,24 @@ func (c *Client) GetBlock(num uint64) (block *blockchain.Block, err error) {
 	return
 }
 
+func (c *Client) GetBlockHeader(num uint64) (h *blockchain.BlockHeader, err error) {
+	err = c.httpGetVal(fmt.Sprintf("/block/%d/header", num), nil, &h)
+	return
+}
+
+func (c *Client) GetLastBlock() (block *blockchain.Block, err error) {
+	var b *blockchain.Block
+	err = c.httpGet("/blocks", url.Values{
+		"order": {"desc"},
+		"limit": {"1"},
+	}, &b, func() {
+		if b != nil {
+			block = b
+		}
+	})
+	return
+}
+
 func (c *Client) GetBlocks(offset uint64, limit int) (blocks []*blockchain.Block, err error) {
 	var block *blockchain.Block
 	err = c.httpGet("/blocks", url.Values{
@@ -97,6 +140,29 @@ func (c *Client) GetBlocks(offset uint64, limit int) (blocks []*blockchain.Block
 	return
 }
 
+// SubscribeBlocks streams blocks after block #from and then new blocks as they are committed by remote node.
+// The stream is reopened after it is closed by server. Subscription is stopped on error of fn or of connection.
+func (c *Client) SubscribeBlocks(from uint64, fn func(block *blockchain.Block) error) error {
+	for {
+		var block *blockchain.Block
+		err := c.httpStream("/blocks/stream", url.Values{
+			"from": {fmt.Sprint(from)},
+		}, &block, func() error {
+			if block == nil {
+				return nil
+			}
+			if err := fn(block); err != nil {
+				return err
+			}
+			from = block.Num
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func (c *Client) PutTx(tx *blockchain.Transaction) (err error) {
 	return c.PutTxs([]*blockchain.Transaction{tx})
 }
@@ -104,3 +170,10 @@ func (c *Client) PutTx(tx *blockchain.Transaction) (err error) {
 func (c *Client) PutTxs(txs []*blockchain.Transaction) (err error) {
 	return c.httpPost("/new-txs", bin.Encode(txs))
 }
+
+// GetTxClaim returns claim of cross-chain outs of source-chain transaction.
+// The claim can be sent to the destination chain by object.NewClaim(...)
+func (c *Client) GetTxClaim(txID uint64) (claim *object.Claim, err error) {
+	err = c.httpGetVal(fmt.Sprintf("/tx/%x/claim", txID), nil, &claim)
+	return
+}
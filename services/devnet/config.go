@@ -0,0 +1,15 @@
+package devnet
+
+import (
+	"flag"
+)
+
+type Config struct {
+	Enabled bool // local devnet mode
+}
+
+func NewConfig() *Config {
+	cfg := &Config{}
+	flag.BoolVar(&cfg.Enabled, "dev", cfg.Enabled, "Local devnet mode: ephemeral keys and db, fresh genesis, in-process miner and /faucet")
+	return cfg
+}
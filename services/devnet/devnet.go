@@ -0,0 +1,144 @@
+package devnet
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/blockchain/db"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/commons/log"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/object"
+)
+
+var errIncorrectAmount = errors.New("devnet: incorrect amount")
+
+// Devnet is the local development network.
+// Master and emission keys are ephemeral; the blockchain starts from a fresh genesis in temporary db;
+// blocks are mined in-process whenever the mempool is non-empty.
+type Devnet struct {
+	MasterKey   *crypto.PrivateKey // signer of blocks
+	EmissionKey *crypto.PrivateKey // signer of faucet emissions
+	dataDir     string             // temporary db dir
+	bc          *db.BlockchainStorage
+	newTxs      chan struct{}
+}
+
+// New generates ephemeral keys and sets temporary db dir and test network to the blockchain config
+func New(cfg *blockchain.Config) (*Devnet, error) {
+	dir, err := ioutil.TempDir("", "likecd-dev")
+	if err != nil {
+		return nil, err
+	}
+	cfg.DataDir = dir
+	cfg.NetworkID = blockchain.NetworkTest
+	cfg.Consensus = blockchain.ConsensusMaster
+	return &Devnet{
+		MasterKey:   crypto.NewPrivateKey(),
+		EmissionKey: crypto.NewPrivateKey(),
+		dataDir:     dir,
+		newTxs:      make(chan struct{}, 1),
+	}, nil
+}
+
+// Genesis returns fresh genesis with the ephemeral keys
+func (d *Devnet) Genesis(cfg *blockchain.Config) *blockchain.Genesis {
+	return &blockchain.Genesis{
+		Network:     cfg.NetworkID,
+		ChainID:     cfg.ChainID,
+		Timestamp:   blockchain.Timestamp(),
+		Consensus:   blockchain.ConsensusMaster,
+		MasterKey:   d.MasterKey.PublicKey,
+		EmissionKey: d.EmissionKey.PublicKey,
+	}
+}
+
+// Start writes genesis to the empty db and starts the miner.
+// Node keys (config.MasterPublicKey, config.EmissionPublicKey) are set to the devnet keys by the storage
+func (d *Devnet) Start(bc *db.BlockchainStorage) error {
+	if err := bc.InitGenesis(d.Genesis(bc.Cfg)); err != nil {
+		return err
+	}
+	d.bc = bc
+	bc.Mempool.AddPutHandler(func([]*blockchain.Transaction) {
+		select {
+		case d.newTxs <- struct{}{}:
+		default: // miner has been already notified
+		}
+	})
+	go d.startMining()
+
+	log.Printf("devnet> db: %s, master key: %s, emission key: %s", bc.Cfg.DataDir, d.MasterKey.PublicKey, d.EmissionKey.PublicKey)
+	return nil
+}
+
+// Storage returns blockchain storage of devnet
+func (d *Devnet) Storage() *db.BlockchainStorage {
+	return d.bc
+}
+
+// Close closes blockchain storage and removes temporary db dir
+func (d *Devnet) Close() error {
+	if d.bc != nil {
+		d.bc.Close()
+	}
+	return os.RemoveAll(d.dataDir)
+}
+
+func (d *Devnet) startMining() {
+	for range d.newTxs {
+		if err := d.mineBlock(); err != nil {
+			log.Error.Printf("devnet> mineBlock Error: %v", err)
+		}
+	}
+}
+
+// mineBlock generates and commits block of all mempool txs; invalid txs are removed from mempool
+func (d *Devnet) mineBlock() error {
+	txs, err := d.bc.Mempool.AllTxs()
+	if err != nil || len(txs) == 0 {
+		return err
+	}
+	var txIDs []uint64
+	var validTxs []*blockchain.Transaction
+	for _, tx := range txs {
+		txIDs = append(txIDs, tx.ID())
+		if err := tx.Verify(d.bc.Cfg); err != nil {
+			log.Error.Printf("devnet> tx 0x%x is rejected: %v", tx.ID(), err)
+			continue
+		}
+		validTxs = append(validTxs, tx)
+	}
+	defer d.bc.Mempool.RemoveTxs(txIDs)
+
+	if len(validTxs) == 0 {
+		return nil
+	}
+	block, err := blockchain.GenerateNewBlock(d.bc.LastBlock().BlockHeader, validTxs, d.MasterKey, d.bc, 0)
+	if err != nil || block == nil {
+		return err
+	}
+	if err = d.bc.PutBlock(block); err != nil {
+		return err
+	}
+	log.Printf("devnet> ⛏ mined block#%d (txs: %d of %d)", block.Num, len(block.Txs), len(txs))
+	return nil
+}
+
+// Faucet puts to mempool emission of amount of asset to address signed by the dev emission key
+func (d *Devnet) Faucet(addr crypto.Address, asset assets.Asset, amount bignum.Int) (*blockchain.Transaction, error) {
+	if amount.Sign() <= 0 {
+		return nil, errIncorrectAmount
+	}
+	tx := object.NewEmission(d.bc.Cfg, d.EmissionKey, asset, amount, object.FaucetTxComment, []*object.EmissionOut{{
+		Address: addr,
+		Delta:   1,
+	}})
+	if err := tx.Verify(d.bc.Cfg); err != nil {
+		return nil, err
+	}
+	return tx, d.bc.Mempool.PutTx(tx)
+}
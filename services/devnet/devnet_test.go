@@ -0,0 +1,59 @@
+package devnet
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/blockchain/db"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/config"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevnet_Faucet(t *testing.T) {
+	cfg := &blockchain.Config{ChainID: 1, VerifyTxsLevel: blockchain.VerifyTxLevel1}
+	dev, err := New(cfg)
+	assert.NoError(t, err)
+	defer func(master, emission *crypto.PublicKey) {
+		config.MasterPublicKey, config.EmissionPublicKey = master, emission
+	}(config.MasterPublicKey, config.EmissionPublicKey)
+	bc := db.NewBlockchainStorage(cfg)
+	defer dev.Close()
+	assert.NoError(t, dev.Start(bc))
+	assert.True(t, config.EmissionPublicKey.Equal(dev.EmissionKey.PublicKey)) // node keys are set by genesis
+	addr := crypto.NewPrivateKey().PublicKey.Address()
+
+	_, err = dev.Faucet(addr, assets.Likecoin, bignum.NewInt(1000))
+
+	assert.NoError(t, err)
+	for i := 0; i < 100 && bc.LastBlock().Num == 0; i++ { // wait for the miner
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, uint64(1), bc.LastBlock().Num)
+	assert.Equal(t, int64(1000), bc.State().Get(assets.Likecoin, addr).Int64())
+	assert.Equal(t, 0, bc.Mempool.Size())
+}
+
+func TestDevnet_Close(t *testing.T) {
+	cfg := &blockchain.Config{ChainID: 1}
+	dev, _ := New(cfg)
+	assert.NoError(t, dev.Start(db.NewBlockchainStorage(cfg)))
+
+	err := dev.Close()
+
+	assert.NoError(t, err)
+	_, err = os.Stat(cfg.DataDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDevnet_Faucet_failByAmount(t *testing.T) {
+	dev := &Devnet{}
+
+	_, err := dev.Faucet(crypto.NilAddress, assets.Likecoin, bignum.NewInt(0))
+
+	assert.Equal(t, errIncorrectAmount, err)
+}
@@ -8,10 +8,14 @@ import (
 )
 
 type Storage struct {
-	mx  sync.RWMutex
-	txs map[uint64]*blockchain.Transaction
+	mx    sync.RWMutex
+	txs   map[uint64]*blockchain.Transaction
+	onPut []PutHandler
 }
 
+// PutHandler is called with new transactions added to mempool
+type PutHandler func(txs []*blockchain.Transaction)
+
 type Info struct {
 	Size int `json:"size"`
 }
@@ -45,16 +49,35 @@ func (s *Storage) SizeOf(txType blockchain.TxType) (count int) {
 	return
 }
 
+func (s *Storage) AddPutHandler(fn PutHandler) {
+	s.onPut = append(s.onPut, fn)
+}
+
 func (s *Storage) PutTx(txs ...*blockchain.Transaction) (err error) {
+	var newTxs []*blockchain.Transaction
 	s.mx.Lock()
-	defer s.mx.Unlock()
-
 	for _, tx := range txs {
+		if _, ok := s.txs[tx.ID()]; !ok {
+			newTxs = append(newTxs, tx)
+		}
 		s.txs[tx.ID()] = tx
 	}
+	s.mx.Unlock()
+
+	if len(newTxs) > 0 {
+		for _, fn := range s.onPut {
+			fn(newTxs)
+		}
+	}
 	return
 }
 
+func (s *Storage) TxByID(txID uint64) *blockchain.Transaction {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.txs[txID]
+}
+
 func (s *Storage) Pop() (tx *blockchain.Transaction) {
 	s.mx.Lock()
 	defer s.mx.Unlock()
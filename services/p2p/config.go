@@ -0,0 +1,40 @@
+package p2p
+
+import (
+	"flag"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	ListenAddr        string        // tcp address of p2p-server (empty - p2p is off)
+	Seeds             []string      // addresses of nodes to connect on start
+	MaxPeers          int           //
+	DiscoveryInterval time.Duration // interval of peers exchange
+}
+
+func NewConfig() *Config {
+	cfg := &Config{
+		MaxPeers:          16,
+		DiscoveryInterval: 30 * time.Second,
+	}
+	flag.StringVar(&cfg.ListenAddr, "p2p", cfg.ListenAddr, "P2P tcp-address, e.g. :8667 (empty - p2p is off)")
+	flag.Var((*addrList)(&cfg.Seeds), "p2p-seeds", "Comma separated P2P addresses of seed nodes")
+	flag.IntVar(&cfg.MaxPeers, "p2p-max-peers", cfg.MaxPeers, "Max count of P2P peers")
+	return cfg
+}
+
+type addrList []string
+
+func (l *addrList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *addrList) Set(s string) error {
+	for _, addr := range strings.Split(s, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			*l = append(*l, addr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package p2p
+
+import "github.com/denisskin/bin"
+
+// ProtocolVersion is the version of p2p protocol
+const ProtocolVersion = 1
+
+// message types
+const (
+	msgHandshake = 1 // handshake
+	msgGetPeers  = 2 // request of peer list
+	msgPeers     = 3 // []address
+	msgInvBlock  = 4 // height of the best block
+	msgInvTxs    = 5 // []txID
+	msgGetBlocks = 6 // getBlocksRequest
+	msgBlocks    = 7 // []*Block
+	msgGetTxs    = 8 // []txID
+	msgTxs       = 9 // []*Transaction
+)
+
+type Message struct {
+	Type int    //
+	Data []byte //
+}
+
+func newMessage(typ int, vals ...interface{}) *Message {
+	return &Message{typ, bin.Encode(vals...)}
+}
+
+func (m *Message) Encode() []byte {
+	return bin.Encode(m.Type, m.Data)
+}
+
+func (m *Message) Decode(data []byte) error {
+	return bin.Decode(data, &m.Type, &m.Data)
+}
+
+func (m *Message) decode(vals ...interface{}) error {
+	return bin.Decode(m.Data, vals...)
+}
+
+type Handshake struct {
+	Version    int    // protocol version
+	Network    int    // networkID
+	ChainID    uint64 //
+	Height     uint64 // number of the best block
+	NodeID     uint64 // random ID of node (to detect self-connections and duplicates)
+	ListenAddr string // p2p address of node (empty - node does not accept connections)
+}
+
+func (h *Handshake) Encode() []byte {
+	return bin.Encode(
+		h.Version,
+		h.Network,
+		h.ChainID,
+		h.Height,
+		h.NodeID,
+		h.ListenAddr,
+	)
+}
+
+func (h *Handshake) Decode(data []byte) error {
+	return bin.Decode(data,
+		&h.Version,
+		&h.Network,
+		&h.ChainID,
+		&h.Height,
+		&h.NodeID,
+		&h.ListenAddr,
+	)
+}
+
+type getBlocksRequest struct {
+	Offset uint64 // number of last known block
+	Limit  int    //
+}
+
+func (r *getBlocksRequest) Encode() []byte {
+	return bin.Encode(r.Offset, r.Limit)
+}
+
+func (r *getBlocksRequest) Decode(data []byte) error {
+	return bin.Decode(data, &r.Offset, &r.Limit)
+}
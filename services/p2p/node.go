@@ -0,0 +1,550 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/denisskin/bin"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/log"
+)
+
+const (
+	blocksBatchSize = 100  // max count of blocks in msgBlocks
+	maxTxsBatchSize = 1000 // max count of txs in msgInvTxs, msgTxs
+	dialTimeout     = 10 * time.Second
+	syncTimeout     = 30 * time.Second // timeout of blocks request
+)
+
+var (
+	errHandshakeExpected = errors.New("p2p: handshake expected")
+	errIncompatibleNode  = errors.New("p2p: incompatible node")
+	errSelfConnection    = errors.New("p2p: self connection")
+	errDuplicatePeer     = errors.New("p2p: duplicate peer")
+	errTooManyPeers      = errors.New("p2p: too many peers")
+	errNodeClosed        = errors.New("p2p: node is closed")
+	errMessageTooLarge   = errors.New("p2p: message is too large")
+)
+
+// Blockchain is the local blockchain of node
+type Blockchain interface {
+	LastBlock() *blockchain.Block
+	GetBlocks(offset uint64, limit int64, desc bool) ([]*blockchain.Block, error)
+	PutBlock(blocks ...*blockchain.Block) error
+	TransactionByID(txID uint64) (*blockchain.Transaction, error)
+}
+
+// Mempool is the pool of unconfirmed transactions of node
+type Mempool interface {
+	PutTx(txs ...*blockchain.Transaction) error
+	TxByID(txID uint64) *blockchain.Transaction
+}
+
+type Node struct {
+	cfg   *Config
+	bcCfg *blockchain.Config
+	bc    Blockchain
+	pool  Mempool
+	id    uint64
+
+	mx     sync.RWMutex
+	ln     net.Listener
+	peers  map[uint64]*Peer // nodeID => peer
+	addrs  map[string]bool  // known p2p addresses of nodes
+	closed bool             //
+
+	mxSync   sync.Mutex // blocks are requested from one peer at a time
+	syncPeer *Peer      // peer with pending request of blocks
+	syncTime time.Time  // time of the request
+}
+
+func NewNode(cfg *Config, bcCfg *blockchain.Config, bc Blockchain, pool Mempool) *Node {
+	n := &Node{
+		cfg:   cfg,
+		bcCfg: bcCfg,
+		bc:    bc,
+		pool:  pool,
+		id:    bin.BytesToUint64(randBytes(8)),
+		peers: map[uint64]*Peer{},
+		addrs: map[string]bool{},
+	}
+	for _, addr := range cfg.Seeds {
+		n.addrs[addr] = true
+	}
+	return n
+}
+
+func randBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Start starts p2p-server (if ListenAddr is set), connects to seed nodes and starts peers discovery
+func (n *Node) Start() error {
+	if n.cfg.ListenAddr != "" {
+		ln, err := net.Listen("tcp", n.cfg.ListenAddr)
+		if err != nil {
+			return err
+		}
+		n.mx.Lock()
+		n.ln = ln
+		n.mx.Unlock()
+		log.Printf("p2p> Start p2p-server on %s (nodeID: %016x)", ln.Addr(), n.id)
+		go n.acceptLoop(ln)
+	}
+	go n.discoveryLoop()
+	return nil
+}
+
+func (n *Node) Close() error {
+	n.mx.Lock()
+	n.closed = true
+	ln, peers := n.ln, n.peers
+	n.peers = map[uint64]*Peer{}
+	n.mx.Unlock()
+
+	for _, p := range peers {
+		p.close()
+	}
+	if ln != nil {
+		return ln.Close()
+	}
+	return nil
+}
+
+// Addr returns actual p2p address of node
+func (n *Node) Addr() string {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+	if n.ln != nil {
+		return n.ln.Addr().String()
+	}
+	return ""
+}
+
+func (n *Node) isClosed() bool {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+	return n.closed
+}
+
+// Peers returns info of connected peers ordered by nodeID
+func (n *Node) Peers() (res []*PeerInfo) {
+	n.mx.RLock()
+	for _, p := range n.peers {
+		res = append(res, p.Info())
+	}
+	n.mx.RUnlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].NodeID < res[j].NodeID })
+	return
+}
+
+func (n *Node) countPeers() int {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+	return len(n.peers)
+}
+
+// Connect connects to remote node by p2p address
+func (n *Node) Connect(addr string) error {
+	if n.isConnected(addr) {
+		return nil
+	}
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err == nil {
+		p := newPeer(conn, false)
+		p.addr = addr
+		err = n.runPeer(p)
+	}
+	if err != nil && err != errDuplicatePeer && err != errTooManyPeers && !n.isSeed(addr) {
+		n.mx.Lock()
+		delete(n.addrs, addr) // forget unavailable, incompatible or own address
+		n.mx.Unlock()
+	}
+	return err
+}
+
+func (n *Node) isSeed(addr string) bool {
+	for _, seed := range n.cfg.Seeds {
+		if seed == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Node) isConnected(addr string) bool {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+	for _, p := range n.peers {
+		if p.addr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Node) acceptLoop(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if !n.isClosed() {
+				log.Error.Printf("p2p> Accept-Error: %v", err)
+			}
+			return
+		}
+		go func() {
+			if err := n.runPeer(newPeer(conn, true)); err != nil {
+				log.Debug.Printf("p2p> inbound peer %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (n *Node) discoveryLoop() {
+	for !n.isClosed() {
+		n.mx.RLock()
+		var addrs []string
+		for addr := range n.addrs {
+			addrs = append(addrs, addr)
+		}
+		n.mx.RUnlock()
+
+		for _, addr := range addrs {
+			if n.countPeers() >= n.cfg.MaxPeers || n.isClosed() {
+				break
+			}
+			if n.isConnected(addr) {
+				continue
+			}
+			go func(addr string) {
+				if err := n.Connect(addr); err != nil {
+					log.Debug.Printf("p2p> connect to %s: %v", addr, err)
+				}
+			}(addr)
+		}
+		// request of peers also keeps connections alive within idleTimeout
+		n.broadcast(newMessage(msgGetPeers))
+		time.Sleep(n.cfg.DiscoveryInterval)
+	}
+}
+
+func (n *Node) handshake() *Handshake {
+	return &Handshake{
+		Version:    ProtocolVersion,
+		Network:    n.bcCfg.NetworkID,
+		ChainID:    n.bcCfg.ChainID,
+		Height:     n.bc.LastBlock().Num,
+		NodeID:     n.id,
+		ListenAddr: n.Addr(),
+	}
+}
+
+// runPeer makes handshake, registers peer and starts its loops
+func (n *Node) runPeer(p *Peer) error {
+	remote, err := p.handshake(n.handshake())
+	if err == nil {
+		err = n.addPeer(p, remote)
+	}
+	if err != nil {
+		p.close()
+		return err
+	}
+	log.Printf("p2p> peer %s connected (nodeID: %016x, height: %d)", p, remote.NodeID, remote.Height)
+
+	go p.writeLoop()
+	go n.readLoop(p)
+
+	n.requestBlocks(p)
+	return nil
+}
+
+func (n *Node) addPeer(p *Peer, remote *Handshake) error {
+	if remote.Version != ProtocolVersion || remote.Network != n.bcCfg.NetworkID || remote.ChainID != n.bcCfg.ChainID {
+		return errIncompatibleNode
+	}
+	if remote.NodeID == n.id {
+		return errSelfConnection
+	}
+	p.info = remote
+	p.setHeight(remote.Height)
+	if p.inbound && remote.ListenAddr != "" {
+		p.addr = advertisedAddr(p.conn, remote.ListenAddr)
+	}
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	if n.closed {
+		return errNodeClosed
+	}
+	if _, ok := n.peers[remote.NodeID]; ok {
+		return errDuplicatePeer
+	}
+	if len(n.peers) >= n.cfg.MaxPeers {
+		return errTooManyPeers
+	}
+	n.peers[remote.NodeID] = p
+	if p.addr != "" {
+		n.addrs[p.addr] = true
+	}
+	return nil
+}
+
+func (n *Node) removePeer(p *Peer) {
+	p.close()
+	n.mx.Lock()
+	if n.peers[p.info.NodeID] == p {
+		delete(n.peers, p.info.NodeID)
+	}
+	n.mx.Unlock()
+
+	n.mxSync.Lock()
+	if n.syncPeer == p {
+		n.syncPeer = nil
+	}
+	n.mxSync.Unlock()
+}
+
+// advertisedAddr returns p2p address of remote node; host of listen address is taken from connection if it is not set
+func advertisedAddr(conn net.Conn, listenAddr string) string {
+	host, port, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host, _, _ = net.SplitHostPort(conn.RemoteAddr().String())
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func (n *Node) readLoop(p *Peer) {
+	defer n.removePeer(p)
+	for {
+		p.conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		msg, err := p.readMessage()
+		if err != nil {
+			if !n.isClosed() {
+				log.Printf("p2p> peer %s disconnected: %v", p, err)
+			}
+			return
+		}
+		if err := n.handleMessage(p, msg); err != nil {
+			log.Error.Printf("p2p> peer %s: message #%d: %v", p, msg.Type, err)
+			return
+		}
+	}
+}
+
+func (n *Node) handleMessage(p *Peer, msg *Message) error {
+	switch msg.Type {
+
+	case msgGetPeers:
+		p.send(newMessage(msgPeers, n.peerAddrs()))
+
+	case msgPeers:
+		var addrs []string
+		if err := msg.decode(&addrs); err != nil {
+			return err
+		}
+		n.mx.Lock()
+		for _, addr := range addrs {
+			if addr != "" && len(n.addrs) < n.cfg.MaxPeers*16 {
+				n.addrs[addr] = true
+			}
+		}
+		n.mx.Unlock()
+
+	case msgInvBlock:
+		var height uint64
+		if err := msg.decode(&height); err != nil {
+			return err
+		}
+		p.setHeight(height)
+		n.requestBlocks(p)
+
+	case msgGetBlocks:
+		var req getBlocksRequest
+		if err := msg.decode(&req); err != nil {
+			return err
+		}
+		if req.Limit <= 0 || req.Limit > blocksBatchSize {
+			req.Limit = blocksBatchSize
+		}
+		blocks, err := n.bc.GetBlocks(req.Offset, int64(req.Limit), false)
+		if err != nil {
+			return err
+		}
+		p.send(newMessage(msgBlocks, blocks))
+
+	case msgBlocks:
+		var blocks []*blockchain.Block
+		if err := msg.decode(&blocks); err != nil {
+			return err
+		}
+		return n.putBlocks(p, blocks)
+
+	case msgInvTxs:
+		var txIDs []uint64
+		if err := msg.decode(&txIDs); err != nil {
+			return err
+		}
+		if unknown := n.unknownTxs(txIDs); len(unknown) > 0 {
+			p.send(newMessage(msgGetTxs, unknown))
+		}
+
+	case msgGetTxs:
+		var txIDs []uint64
+		if err := msg.decode(&txIDs); err != nil {
+			return err
+		}
+		var txs []*blockchain.Transaction
+		for _, txID := range txIDs {
+			if tx := n.pool.TxByID(txID); tx != nil && len(txs) < maxTxsBatchSize {
+				txs = append(txs, tx)
+			}
+		}
+		if len(txs) > 0 {
+			p.send(newMessage(msgTxs, txs))
+		}
+
+	case msgTxs:
+		var txs []*blockchain.Transaction
+		if err := msg.decode(&txs); err != nil {
+			return err
+		}
+		return n.putTxs(txs)
+	}
+	return nil
+}
+
+func (n *Node) peerAddrs() (addrs []string) {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+	for _, p := range n.peers {
+		if p.addr != "" {
+			addrs = append(addrs, p.addr)
+		}
+	}
+	return
+}
+
+// requestBlocks requests next blocks from peer if peer has higher height; only one request at a time
+func (n *Node) requestBlocks(p *Peer) {
+	n.mxSync.Lock()
+	defer n.mxSync.Unlock()
+
+	if n.syncPeer != nil && time.Since(n.syncTime) < syncTimeout {
+		return
+	}
+	if last := n.bc.LastBlock().Num; p.Height() > last {
+		if p.send(newMessage(msgGetBlocks, &getBlocksRequest{last, blocksBatchSize})) {
+			n.syncPeer, n.syncTime = p, time.Now()
+		}
+	}
+}
+
+func (n *Node) putBlocks(p *Peer, blocks []*blockchain.Block) error {
+	n.mxSync.Lock()
+	if n.syncPeer == p {
+		n.syncPeer = nil
+	}
+	n.mxSync.Unlock()
+
+	// skip known blocks
+	last := n.bc.LastBlock().Num
+	for len(blocks) > 0 && blocks[0].Num <= last {
+		blocks = blocks[1:]
+	}
+	if len(blocks) > 0 {
+		if err := n.bc.PutBlock(blocks...); err != nil {
+			if n.bc.LastBlock().Num >= blocks[0].Num { // blocks have been received from another source
+				return nil
+			}
+			return err
+		}
+		p.setHeight(blocks[len(blocks)-1].Num)
+		log.Printf("p2p> ✅ received block#%d from %s", blocks[len(blocks)-1].Num, p)
+	}
+	// continue sync by the best of peers
+	n.mx.RLock()
+	var best *Peer
+	for _, peer := range n.peers {
+		if best == nil || peer.Height() > best.Height() {
+			best = peer
+		}
+	}
+	n.mx.RUnlock()
+	if best != nil {
+		n.requestBlocks(best)
+	}
+	return nil
+}
+
+func (n *Node) unknownTxs(txIDs []uint64) (unknown []uint64) {
+	for _, txID := range txIDs {
+		if n.pool.TxByID(txID) != nil {
+			continue
+		}
+		if tx, err := n.bc.TransactionByID(txID); err != nil || tx != nil {
+			continue
+		}
+		if unknown = append(unknown, txID); len(unknown) >= maxTxsBatchSize {
+			break
+		}
+	}
+	return
+}
+
+func (n *Node) putTxs(txs []*blockchain.Transaction) error {
+	var valid []*blockchain.Transaction
+	for _, tx := range txs {
+		if err := tx.Verify(n.bcCfg); err != nil {
+			return err
+		}
+		if len(n.unknownTxs([]uint64{tx.ID()})) > 0 {
+			valid = append(valid, tx)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+	return n.pool.PutTx(valid...)
+}
+
+func (n *Node) broadcast(msg *Message) {
+	n.mx.RLock()
+	defer n.mx.RUnlock()
+	for _, p := range n.peers {
+		p.send(msg)
+	}
+}
+
+// AnnounceBlocks announces the new best block to peers (is db.CommitHandler)
+func (n *Node) AnnounceBlocks(blocks []*blockchain.Block) {
+	if len(blocks) > 0 {
+		n.broadcast(newMessage(msgInvBlock, blocks[len(blocks)-1].Num))
+	}
+}
+
+// AnnounceTxs announces new transactions of mempool to peers (is mempool.PutHandler)
+func (n *Node) AnnounceTxs(txs []*blockchain.Transaction) {
+	for len(txs) > 0 {
+		m := len(txs)
+		if m > maxTxsBatchSize {
+			m = maxTxsBatchSize
+		}
+		txIDs := make([]uint64, m)
+		for i, tx := range txs[:m] {
+			txIDs[i] = tx.ID()
+		}
+		n.broadcast(newMessage(msgInvTxs, txIDs))
+		txs = txs[m:]
+	}
+}
@@ -0,0 +1,218 @@
+package p2p
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/denisskin/bin"
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
+	"github.com/likecoin-pro/likecoin/object"
+	"github.com/likecoin-pro/likecoin/services/mempool"
+	"github.com/stretchr/testify/assert"
+)
+
+var testBCCfg = &blockchain.Config{
+	NetworkID: blockchain.NetworkTest,
+	ChainID:   1,
+}
+
+// testChain is in-memory blockchain without verification of blocks
+type testChain struct {
+	mx       sync.Mutex
+	blocks   []*blockchain.Block
+	onCommit func([]*blockchain.Block)
+}
+
+func (c *testChain) LastBlock() *blockchain.Block {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if len(c.blocks) == 0 {
+		return blockchain.NewBlock(&blockchain.BlockHeader{}, nil)
+	}
+	return c.blocks[len(c.blocks)-1]
+}
+
+func (c *testChain) GetBlocks(offset uint64, limit int64, desc bool) ([]*blockchain.Block, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if offset >= uint64(len(c.blocks)) {
+		return nil, nil
+	}
+	blocks := c.blocks[offset:]
+	if int64(len(blocks)) > limit {
+		blocks = blocks[:limit]
+	}
+	return blocks, nil
+}
+
+func (c *testChain) PutBlock(blocks ...*blockchain.Block) error {
+	c.mx.Lock()
+	for _, b := range blocks {
+		if b.Num != uint64(len(c.blocks))+1 {
+			c.mx.Unlock()
+			return errors.New("invalid block num")
+		}
+		c.blocks = append(c.blocks, b)
+	}
+	c.mx.Unlock()
+	if c.onCommit != nil {
+		c.onCommit(blocks)
+	}
+	return nil
+}
+
+func (c *testChain) TransactionByID(txID uint64) (*blockchain.Transaction, error) {
+	return nil, nil
+}
+
+func (c *testChain) height() uint64 {
+	return c.LastBlock().Num
+}
+
+func newTestBlocks(from, to uint64) (blocks []*blockchain.Block) {
+	for num := from; num <= to; num++ {
+		blocks = append(blocks, blockchain.NewBlock(&blockchain.BlockHeader{
+			Network: testBCCfg.NetworkID,
+			ChainID: testBCCfg.ChainID,
+			Num:     num,
+		}, nil))
+	}
+	return
+}
+
+type testNode struct {
+	*Node
+	chain *testChain
+	pool  *mempool.Storage
+}
+
+func newTestNode(t *testing.T, bcCfg *blockchain.Config, nBlocks uint64, seeds ...string) *testNode {
+	chain := &testChain{blocks: newTestBlocks(1, nBlocks)}
+	pool := mempool.NewStorage()
+	node := NewNode(&Config{
+		ListenAddr:        "127.0.0.1:0",
+		Seeds:             seeds,
+		MaxPeers:          8,
+		DiscoveryInterval: 50 * time.Millisecond,
+	}, bcCfg, chain, pool)
+	chain.onCommit = node.AnnounceBlocks
+	pool.AddPutHandler(node.AnnounceTxs)
+
+	err := node.Start()
+	assert.NoError(t, err)
+	return &testNode{node, chain, pool}
+}
+
+func waitFor(cond func() bool) bool {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cond() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNode_peersDiscovery(t *testing.T) {
+	a := newTestNode(t, testBCCfg, 0)
+	defer a.Close()
+	b := newTestNode(t, testBCCfg, 0, a.Addr())
+	defer b.Close()
+	c := newTestNode(t, testBCCfg, 0, a.Addr())
+	defer c.Close()
+
+	ok := waitFor(func() bool {
+		return len(a.Peers()) == 2 && len(b.Peers()) == 2 && len(c.Peers()) == 2
+	})
+
+	assert.True(t, ok)
+}
+
+func TestPeer_readMessage(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	p := newPeer(c2, true)
+	defer p.close()
+
+	go bin.NewWriter(c1).WriteVar(newMessage(msgInvBlock, uint64(123)))
+	msg, err := p.readMessage()
+
+	assert.NoError(t, err)
+	assert.Equal(t, msgInvBlock, msg.Type)
+}
+
+func TestPeer_readMessage_failByLength(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	p := newPeer(c2, true)
+	defer p.close()
+
+	go bin.NewWriter(c1).WriteVarInt(maxMessageSize + 1)
+	_, err := p.readMessage()
+
+	assert.Equal(t, errMessageTooLarge, err)
+}
+
+func TestNode_incompatibleChain(t *testing.T) {
+	a := newTestNode(t, testBCCfg, 0)
+	defer a.Close()
+	b := newTestNode(t, &blockchain.Config{NetworkID: testBCCfg.NetworkID, ChainID: 2}, 0)
+	defer b.Close()
+
+	err := b.Connect(a.Addr())
+
+	assert.Equal(t, errIncompatibleNode, err)
+	assert.Equal(t, 0, len(a.Peers()))
+	assert.Equal(t, 0, len(b.Peers()))
+}
+
+func TestNode_blocksGossip(t *testing.T) {
+	a := newTestNode(t, testBCCfg, 250)
+	defer a.Close()
+	b := newTestNode(t, testBCCfg, 0, a.Addr())
+	defer b.Close()
+	c := newTestNode(t, testBCCfg, 0, b.Addr())
+	defer c.Close()
+
+	// initial sync
+	ok := waitFor(func() bool {
+		return b.chain.height() == 250 && c.chain.height() == 250
+	})
+	assert.True(t, ok)
+
+	// announcement of new block
+	err := a.chain.PutBlock(newTestBlocks(251, 251)...)
+	assert.NoError(t, err)
+
+	ok = waitFor(func() bool {
+		return b.chain.height() == 251 && c.chain.height() == 251
+	})
+	assert.True(t, ok)
+}
+
+func TestNode_txsGossip(t *testing.T) {
+	a := newTestNode(t, testBCCfg, 0)
+	defer a.Close()
+	b := newTestNode(t, testBCCfg, 0, a.Addr())
+	defer b.Close()
+	c := newTestNode(t, testBCCfg, 0, b.Addr())
+	defer c.Close()
+	waitFor(func() bool { return len(b.Peers()) >= 2 })
+
+	aliceKey := crypto.NewPrivateKeyBySecret("alice::Alice secret")
+	bobAddr := crypto.NewPrivateKeyBySecret("bob::Bob secret").PublicKey.Address()
+	tx := object.NewSimpleTransfer(testBCCfg, aliceKey, bobAddr, bignum.NewInt(100), assets.Likecoin, "", 0, 0)
+
+	err := a.pool.PutTx(tx)
+	assert.NoError(t, err)
+
+	ok := waitFor(func() bool {
+		return c.pool.TxByID(tx.ID()) != nil
+	})
+	assert.True(t, ok)
+}
@@ -0,0 +1,149 @@
+package p2p
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/denisskin/bin"
+)
+
+const (
+	handshakeTimeout = 10 * time.Second
+	writeTimeout     = 30 * time.Second
+	idleTimeout      = 3 * time.Minute // max interval between messages of peer
+	peerQueueSize    = 256
+	maxMessageSize   = 32 << 20
+)
+
+type Peer struct {
+	conn     net.Conn
+	r        *bin.Reader
+	w        *bin.Writer
+	inbound  bool       // peer has connected to the node
+	info     *Handshake // remote handshake
+	addr     string     // p2p address of remote node (empty - remote node does not accept connections)
+	height   uint64     // number of the best block of remote node (atomic)
+	queue    chan *Message
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+type PeerInfo struct {
+	NodeID  uint64 `json:"node_id"` //
+	Addr    string `json:"addr"`    // p2p address of remote node
+	Remote  string `json:"remote"`  // remote address of connection
+	Inbound bool   `json:"inbound"` //
+	Height  uint64 `json:"height"`  // the best block of remote node
+}
+
+func newPeer(conn net.Conn, inbound bool) *Peer {
+	return &Peer{
+		conn:    conn,
+		r:       bin.NewReader(conn),
+		w:       bin.NewWriter(conn),
+		inbound: inbound,
+		queue:   make(chan *Message, peerQueueSize),
+		done:    make(chan struct{}),
+	}
+}
+
+func (p *Peer) String() string {
+	return p.conn.RemoteAddr().String()
+}
+
+func (p *Peer) Info() *PeerInfo {
+	return &PeerInfo{
+		NodeID:  p.info.NodeID,
+		Addr:    p.addr,
+		Remote:  p.conn.RemoteAddr().String(),
+		Inbound: p.inbound,
+		Height:  p.Height(),
+	}
+}
+
+func (p *Peer) Height() uint64 {
+	return atomic.LoadUint64(&p.height)
+}
+
+func (p *Peer) setHeight(h uint64) {
+	for {
+		cur := atomic.LoadUint64(&p.height)
+		if h <= cur || atomic.CompareAndSwapUint64(&p.height, cur, h) {
+			return
+		}
+	}
+}
+
+// handshake exchanges handshakes with remote node
+func (p *Peer) handshake(h *Handshake) (remote *Handshake, err error) {
+	p.conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer p.conn.SetDeadline(time.Time{})
+
+	if err = p.w.WriteVar(newMessage(msgHandshake, h)); err != nil {
+		return
+	}
+	msg, err := p.readMessage()
+	if err != nil {
+		return
+	}
+	if msg.Type != msgHandshake {
+		return nil, errHandshakeExpected
+	}
+	remote = new(Handshake)
+	err = msg.decode(remote)
+	return
+}
+
+// readMessage reads the next frame of peer; frames longer than maxMessageSize are rejected before allocation
+func (p *Peer) readMessage() (msg *Message, err error) {
+	size, err := p.r.ReadVarInt()
+	if err != nil {
+		return
+	}
+	if size < 0 || size > maxMessageSize {
+		return nil, errMessageTooLarge
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(p.r, data); err != nil {
+		return
+	}
+	msg = new(Message)
+	err = msg.Decode(data)
+	return
+}
+
+// send puts message to the queue of peer; message is dropped if the queue is full
+func (p *Peer) send(msg *Message) bool {
+	select {
+	case p.queue <- msg:
+		return true
+	case <-p.done:
+	default:
+	}
+	return false
+}
+
+func (p *Peer) writeLoop() {
+	for {
+		select {
+		case msg := <-p.queue:
+			p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := p.w.WriteVar(msg); err != nil {
+				p.close()
+				return
+			}
+		case <-p.done:
+			return
+		}
+	}
+}
+
+func (p *Peer) close() {
+	p.doneOnce.Do(func() {
+		close(p.done)
+		p.conn.Close()
+	})
+}
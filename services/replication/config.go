@@ -0,0 +1,31 @@
+package replication
+
+import (
+	"flag"
+	"strings"
+)
+
+type Config struct {
+	Peers []string // API addresses of upstream nodes
+}
+
+func NewConfig() *Config {
+	cfg := &Config{}
+	flag.Var((*peerList)(&cfg.Peers), "peers", "Comma separated API addresses of upstream nodes for replication (by default: "+defaultPeer+")")
+	return cfg
+}
+
+type peerList []string
+
+func (l *peerList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *peerList) Set(s string) error {
+	for _, addr := range strings.Split(s, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			*l = append(*l, addr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package replication
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/blockchain/db"
+	"github.com/likecoin-pro/likecoin/commons/log"
+)
+
+const (
+	pipelineWorkers   = 4    // count of goroutines fetching ranges of blocks
+	pipelineBatchSize = 100  // count of blocks in range
+	pipelineMinLag    = 1000 // pipelined sync is used if local blockchain is behind upstreams by more blocks
+)
+
+var errIncompleteRange = errors.New("incomplete range of blocks")
+
+// syncJob is the range of blocks fetched by pipeline
+type syncJob struct {
+	offset uint64              // the last block before the range
+	src    *upstream           // upstream served the blocks
+	blocks []*blockchain.Block //
+	err    error               //
+	done   chan struct{}       // is closed when blocks are fetched and verified
+}
+
+// bestHeight returns number of the best block of healthy upstreams
+func (s *Service) bestHeight() (height uint64) {
+	for _, u := range s.healthyUpstreams() {
+		block, err := u.GetLastBlock()
+		if err != nil {
+			log.Error.Printf("replication> %s: client.GetLastBlock-Error: %v (backoff %v)", u, err, u.fail())
+			continue
+		}
+		if block != nil && block.Num > height {
+			height = block.Num
+		}
+	}
+	return
+}
+
+// pipelinedSync downloads blocks up to target block.
+// Several goroutines fetch ranges of blocks concurrently and verify headers and txs of the ranges;
+// the ranges are committed in order.
+func (s *Service) pipelinedSync(target uint64) error {
+	from := s.bc.LastBlock().Num
+	s.progress.start(from, target)
+	defer s.progress.stop()
+
+	log.Printf("replication> pipelined sync from block#%d to block#%d", from, target)
+
+	jobs := make(chan *syncJob, pipelineWorkers)    // jobs for fetchers
+	queue := make(chan *syncJob, pipelineWorkers*2) // jobs in order of commit
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// make ranges
+	go func() {
+		defer close(jobs)
+		defer close(queue)
+		for offset := from; offset < target; offset += pipelineBatchSize {
+			job := &syncJob{offset: offset, done: make(chan struct{})}
+			select {
+			case queue <- job:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// fetch and verify ranges
+	for i := 0; i < pipelineWorkers; i++ {
+		go func() {
+			for job := range jobs {
+				job.src, job.blocks, job.err = s.fetchVerifiedBlocks(job.offset, target)
+				close(job.done)
+			}
+		}()
+	}
+
+	// commit ranges in order
+	for job := range queue {
+		<-job.done
+		if job.err != nil {
+			return job.err
+		}
+		if err := s.bc.PutVerifiedBlock(job.blocks...); err != nil {
+			if !db.IsInvalidBlock(err) { // local error of node; blocks will be fetched again
+				return fmt.Errorf("bc.PutBlock-Error: %v", err)
+			}
+			job.src.ban()
+			return fmt.Errorf("%s: bc.PutBlock-Error: %v (upstream is banned for %v)", job.src, err, banDuration)
+		}
+		job.src.success()
+		log.Printf("replication> ✅ replicated block#%d of %d", job.blocks[len(job.blocks)-1].Num, target)
+	}
+	return nil
+}
+
+// fetchVerifiedBlocks fetches range of blocks after offset from a healthy upstream;
+// verifies headers, txs and hash of the last block by other upstreams
+func (s *Service) fetchVerifiedBlocks(offset, target uint64) (src *upstream, blocks []*blockchain.Block, err error) {
+	size := uint64(pipelineBatchSize)
+	if offset+size > target {
+		size = target - offset
+	}
+	for attempt := 0; attempt < len(s.upstreams); attempt++ {
+		ups := s.healthyUpstreams()
+		if len(ups) == 0 {
+			return nil, nil, errNoHealthyUpstreams
+		}
+		src = ups[0]
+		if blocks, err = src.GetBlocks(offset, int(size)); err != nil {
+			log.Error.Printf("replication> %s: client.GetBlocks-Error: %v (backoff %v)", src, err, src.fail())
+			continue
+		}
+		if uint64(len(blocks)) < size {
+			err = errIncompleteRange
+			src.fail()
+			continue
+		}
+		if err = s.verifyBlocks(offset, blocks); err != nil {
+			src.ban()
+			log.Error.Printf("replication> %s: verify blocks Error: %v (upstream is banned for %v)", src, err, banDuration)
+			continue
+		}
+		if err = s.crossCheck(src, ups[1:], blocks[len(blocks)-1]); err != nil {
+			continue
+		}
+		return
+	}
+	return
+}
+
+// verifyBlocks verifies headers of range of blocks and txs (in parallel)
+func (s *Service) verifyBlocks(offset uint64, blocks []*blockchain.Block) error {
+	var pre *blockchain.BlockHeader
+	for i, block := range blocks {
+		if block.Num != offset+uint64(i)+1 {
+			return blockchain.ErrInvalidBlockNum
+		}
+		if err := block.Verify(pre, s.bc.Cfg); err != nil {
+			return err
+		}
+		pre = block.BlockHeader
+	}
+	if s.bc.Cfg.VerifyTxsLevel >= blockchain.VerifyTxLevel1 {
+		return blockchain.VerifyTxs(s.bc.Cfg, blockchain.BlocksTxs(blocks))
+	}
+	return nil
+}
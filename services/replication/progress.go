@@ -0,0 +1,57 @@
+package replication
+
+import (
+	"sync"
+	"time"
+
+	"github.com/likecoin-pro/likecoin/blockchain/db"
+)
+
+// progress is the progress of initial sync
+type progress struct {
+	mx        sync.Mutex
+	syncing   bool      //
+	startTime time.Time // start of sync
+	startNum  uint64    // the last block on start of sync
+	target    uint64    // the best block of upstreams
+}
+
+func (p *progress) start(current, target uint64) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.syncing, p.startTime, p.startNum, p.target = true, time.Now(), current, target
+}
+
+func (p *progress) stop() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.syncing = false
+}
+
+func (p *progress) setTarget(target uint64) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if target > p.target {
+		p.target = target
+	}
+}
+
+func (p *progress) info(current uint64) *db.SyncInfo {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	inf := &db.SyncInfo{
+		Syncing: p.syncing,
+		Current: current,
+		Target:  p.target,
+	}
+	if inf.Target < current {
+		inf.Target = current
+	}
+	if p.syncing && current > p.startNum {
+		if sec := time.Since(p.startTime).Seconds(); sec > 0 {
+			inf.BlocksPerSec = float64(current-p.startNum) / sec
+			inf.ETA = int64(float64(inf.Target-current) / inf.BlocksPerSec)
+		}
+	}
+	return inf
+}
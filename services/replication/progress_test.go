@@ -0,0 +1,34 @@
+package replication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress_info(t *testing.T) {
+	var p progress
+	p.start(1000, 5000)
+	p.startTime = time.Now().Add(-10 * time.Second)
+
+	inf := p.info(2000)
+
+	assert.True(t, inf.Syncing)
+	assert.Equal(t, uint64(2000), inf.Current)
+	assert.Equal(t, uint64(5000), inf.Target)
+	assert.InDelta(t, 100, inf.BlocksPerSec, 1)
+	assert.InDelta(t, 30, inf.ETA, 1)
+}
+
+func TestProgress_info_stopped(t *testing.T) {
+	var p progress
+	p.start(1000, 5000)
+	p.stop()
+
+	inf := p.info(5000)
+
+	assert.False(t, inf.Syncing)
+	assert.Equal(t, uint64(5000), inf.Target)
+	assert.Equal(t, 0.0, inf.BlocksPerSec)
+}
@@ -1,46 +1,141 @@
 package replication
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/likecoin-pro/likecoin/blockchain"
 	"github.com/likecoin-pro/likecoin/blockchain/db"
 	"github.com/likecoin-pro/likecoin/commons/log"
 	"github.com/likecoin-pro/likecoin/services/client"
 )
 
+const defaultPeer = client.DefaultAPIAddress
+
+// maxCrossCheckPeers is the max count of other upstreams which confirm hash of received blocks
+const maxCrossCheckPeers = 2
+
+var (
+	errNoHealthyUpstreams = errors.New("no healthy upstreams")
+	errHeadersMismatch    = errors.New("block headers mismatch between upstreams")
+	errUpstreamBanned     = errors.New("upstream has served invalid block")
+)
+
 type Service struct {
-	client *client.Client
-	bc     *db.BlockchainStorage
+	upstreams []*upstream
+	next      uint32 // index of next upstream (round-robin)
+	bc        *db.BlockchainStorage
+	progress  progress
 }
 
-func NewService(cl *client.Client, bc *db.BlockchainStorage) *Service {
-	if cl == nil {
-		cl = client.NewClient("")
+func NewService(cfg *Config, bc *db.BlockchainStorage) *Service {
+	peers := cfg.Peers
+	if len(peers) == 0 {
+		peers = []string{defaultPeer}
 	}
-	return &Service{
-		client: cl,
-		bc:     bc,
+	s := &Service{bc: bc}
+	for _, addr := range peers {
+		s.upstreams = append(s.upstreams, newUpstream(client.NewClient(addr)))
 	}
+	return s
 }
 
 func (s *Service) StartReplication() {
+	s.bc.SetSyncInfo(s.SyncInfo)
 	go s.startBlockchainReplication()
 	go s.startMempoolReplication()
 }
 
+// healthyUpstreams returns healthy upstreams starting from the next one by round-robin
+func (s *Service) healthyUpstreams() (res []*upstream) {
+	n := len(s.upstreams)
+	next := int(atomic.AddUint32(&s.next, 1))
+	for i := 0; i < n; i++ {
+		if u := s.upstreams[(next+i)%n]; u.healthy() {
+			res = append(res, u)
+		}
+	}
+	return
+}
+
+// SyncInfo returns progress of replication
+func (s *Service) SyncInfo() *db.SyncInfo {
+	return s.progress.info(s.bc.LastBlock().Num)
+}
+
 func (s *Service) startBlockchainReplication() {
+	// initial sync
+	if target := s.bestHeight(); target > s.bc.LastBlock().Num+pipelineMinLag {
+		if err := s.pipelinedSync(target); err != nil {
+			log.Error.Printf("replication> pipelinedSync Error: %v", err)
+		}
+	}
 	for {
 		nBlocks, err := s.loadBlocksBatch(s.bc.LastBlock().Num, 100)
 		if err != nil {
 			log.Error.Printf("replication> loadBlocksBatch Error: %v", err)
 		}
-		if nBlocks == 0 || err != nil {
+		if err != nil {
 			time.Sleep(5 * time.Second)
+		} else if nBlocks == 0 {
+			// blockchain is synced; follow new blocks by stream
+			if err = s.followBlocks(); err != nil {
+				log.Error.Printf("replication> followBlocks Error: %v", err)
+				time.Sleep(time.Second)
+			}
+		}
+	}
+}
+
+// followBlocks subscribes to stream of new blocks of a healthy upstream and commits received blocks
+func (s *Service) followBlocks() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("replication> followBlocks-Panic: %v", r)
+		}
+	}()
+
+	ups := s.healthyUpstreams()
+	if len(ups) == 0 {
+		return errNoHealthyUpstreams
+	}
+	src, others := ups[0], ups[1:]
+
+	var errLocal bool // local error of node; upstream is not backed off
+	err = src.SubscribeBlocks(s.bc.LastBlock().Num, func(block *blockchain.Block) error {
+		if block.Num <= s.bc.LastBlock().Num { // block has been received from another source
+			return nil
 		}
+		s.progress.setTarget(block.Num)
+		if err := s.crossCheck(src, others, block); err != nil {
+			return err
+		}
+		if err := s.bc.PutBlock(block); err != nil {
+			if s.bc.LastBlock().Num >= block.Num {
+				return nil
+			}
+			if !db.IsInvalidBlock(err) { // the block will be received again
+				errLocal = true
+				return err
+			}
+			src.ban()
+			log.Error.Printf("replication> %s: bc.PutBlock-Error: %v (upstream is banned for %v)", src, err, banDuration)
+			return errUpstreamBanned
+		}
+		src.success()
+		log.Printf("replication> ✅ replicated block#%d from %s", block.Num, src)
+		return nil
+	})
+	if err != nil && err != errUpstreamBanned && !errLocal {
+		src.fail()
 	}
+	return
 }
 
+// loadBlocksBatch fetches blocks from the first healthy upstream, cross-checks them by other upstreams and commits
 func (s *Service) loadBlocksBatch(blockOffset uint64, batchSize int) (n int, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -48,22 +143,77 @@ func (s *Service) loadBlocksBatch(blockOffset uint64, batchSize int) (n int, err
 		}
 	}()
 
-	blocks, err := s.client.GetBlocks(blockOffset, batchSize)
+	ups := s.healthyUpstreams()
+	if len(ups) == 0 {
+		return 0, errNoHealthyUpstreams
+	}
+	src, others := ups[0], ups[1:]
+
+	blocks, err := src.GetBlocks(blockOffset, batchSize)
 	if err != nil {
-		log.Error.Printf("replication> client.GetBlocks-Error: %v", err)
-		return
+		log.Error.Printf("replication> %s: client.GetBlocks-Error: %v (backoff %v)", src, err, src.fail())
+		return 0, nil
 	}
 	if len(blocks) == 0 {
 		return
 	}
-	if err = s.bc.PutBlock(blocks...); err != nil {
-		log.Error.Printf("replication> bc.PutBlock-Error: %v", err)
+	if err = s.crossCheck(src, others, blocks[len(blocks)-1]); err != nil {
 		return
 	}
-	log.Printf("replication> ✅ replicated block#%d ", blocks[len(blocks)-1].Num)
+	if err = s.bc.PutBlock(blocks...); err != nil {
+		if s.bc.LastBlock().Num >= blocks[0].Num { // blocks have been received from another source
+			return 0, nil
+		}
+		if !db.IsInvalidBlock(err) { // local error of node; retry after pause
+			return 0, err
+		}
+		src.ban()
+		log.Error.Printf("replication> %s: bc.PutBlock-Error: %v (upstream is banned for %v)", src, err, banDuration)
+		return 0, nil
+	}
+	src.success()
+	log.Printf("replication> ✅ replicated block#%d from %s", blocks[len(blocks)-1].Num, src)
 	return len(blocks), nil
 }
 
+// crossCheck compares hash of the last block of batch with headers of other upstreams.
+// Blocks are linked by hashes, so the last block confirms the whole batch.
+// Upstreams of the minority are backed off; the batch is not committed if the source is in the minority.
+func (s *Service) crossCheck(src *upstream, others []*upstream, last *blockchain.Block) error {
+	hash := last.Hash()
+	var agreed, disagreed []*upstream
+	for _, u := range others {
+		if len(agreed)+len(disagreed) >= maxCrossCheckPeers {
+			break
+		}
+		h, err := u.GetBlockHeader(last.Num)
+		if err != nil {
+			log.Error.Printf("replication> %s: client.GetBlockHeader-Error: %v (backoff %v)", u, err, u.fail())
+			continue
+		}
+		if h == nil { // upstream has not got the block yet
+			continue
+		}
+		if bytes.Equal(h.Hash(), hash) {
+			agreed = append(agreed, u)
+		} else {
+			disagreed = append(disagreed, u)
+		}
+	}
+	if len(disagreed) == 0 {
+		return nil
+	}
+	log.Error.Printf("replication> block#%d: %v (%s and %d agreed, %d disagreed)", last.Num, errHeadersMismatch, src, len(agreed), len(disagreed))
+	if len(disagreed) >= len(agreed)+1 {
+		src.fail()
+		return errHeadersMismatch
+	}
+	for _, u := range disagreed {
+		u.fail()
+	}
+	return nil
+}
+
 func (s *Service) startMempoolReplication() {
 
 	// todo: (it`s temporary scheme) refactor me! use decentralize replication;
@@ -92,10 +242,15 @@ func (s *Service) putMempoolTxs() (ok bool, err error) {
 	if len(txs) == 0 {
 		return
 	}
-	//-- put to remote node
-	err = s.client.PutTxs(txs)
-	if err != nil {
-		log.Error.Printf("replication> client.PutTxs-Error: %v", err)
+	//-- put to remote nodes
+	for _, u := range s.healthyUpstreams() {
+		if err := u.PutTxs(txs); err != nil {
+			log.Error.Printf("replication> %s: client.PutTxs-Error: %v (backoff %v)", u, err, u.fail())
+		} else {
+			ok = true
+		}
+	}
+	if !ok {
 		return
 	}
 	//-- remove from mempool
@@ -0,0 +1,60 @@
+package replication
+
+import (
+	"sync"
+	"time"
+
+	"github.com/likecoin-pro/likecoin/services/client"
+)
+
+const (
+	minBackoff  = time.Second
+	maxBackoff  = 5 * time.Minute
+	banDuration = time.Hour
+)
+
+// upstream is the upstream node with its health state
+type upstream struct {
+	*client.Client
+
+	mx       sync.Mutex
+	failures uint      // count of consecutive failures
+	until    time.Time // upstream is not used until the time
+}
+
+func newUpstream(cl *client.Client) *upstream {
+	return &upstream{Client: cl}
+}
+
+func (u *upstream) healthy() bool {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	return time.Now().After(u.until)
+}
+
+func (u *upstream) success() {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	u.failures, u.until = 0, time.Time{}
+}
+
+// fail backs off upstream exponentially by count of consecutive failures
+func (u *upstream) fail() time.Duration {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	d := minBackoff << u.failures
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	} else {
+		u.failures++
+	}
+	u.until = time.Now().Add(d)
+	return d
+}
+
+// ban disables upstream which has served invalid blocks
+func (u *upstream) ban() {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	u.until = time.Now().Add(banDuration)
+}
@@ -0,0 +1,41 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/likecoin-pro/likecoin/services/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstream_fail(t *testing.T) {
+	u := newUpstream(client.NewClient("http://localhost:8666"))
+
+	d1 := u.fail()
+	d2 := u.fail()
+	d3 := u.fail()
+
+	assert.False(t, u.healthy())
+	assert.Equal(t, minBackoff, d1)
+	assert.Equal(t, 2*minBackoff, d2)
+	assert.Equal(t, 4*minBackoff, d3)
+}
+
+func TestUpstream_fail_maxBackoff(t *testing.T) {
+	u := newUpstream(client.NewClient("http://localhost:8666"))
+
+	for i := 0; i < 100; i++ {
+		u.fail()
+	}
+
+	assert.Equal(t, maxBackoff, u.fail())
+}
+
+func TestUpstream_success(t *testing.T) {
+	u := newUpstream(client.NewClient("http://localhost:8666"))
+	u.ban()
+
+	u.success()
+
+	assert.True(t, u.healthy())
+	assert.Equal(t, minBackoff, u.fail())
+}
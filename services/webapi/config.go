@@ -2,12 +2,21 @@ package webapi
 
 import (
 	"flag"
+
+	"github.com/likecoin-pro/likecoin/assets"
+	"github.com/likecoin-pro/likecoin/blockchain"
+	"github.com/likecoin-pro/likecoin/commons/bignum"
+	"github.com/likecoin-pro/likecoin/crypto"
 )
 
 type Config struct {
 	HTTPConnStr string
+	Faucet      Faucet // nil - /faucet is off
 }
 
+// Faucet issues coins to address (devnet mode)
+type Faucet func(addr crypto.Address, asset assets.Asset, amount bignum.Int) (*blockchain.Transaction, error)
+
 func NewConfig() *Config {
 	cfg := &Config{
 		HTTPConnStr: ":8666",
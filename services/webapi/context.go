@@ -6,11 +6,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/denisskin/bin"
 	"github.com/likecoin-pro/likecoin/assets"
@@ -29,6 +32,7 @@ type Context struct {
 	rw        http.ResponseWriter
 	bc        *db.BlockchainStorage
 	urlPrefix string
+	faucet    Faucet // nil - /faucet is off
 }
 
 func NewContext(
@@ -46,16 +50,27 @@ func NewContext(
 }
 
 const reAddress = `(Like[a-zA-Z0-9]+|@[a-zA-Z][0-9a-zA-Z\-]+)`
+const reAsset = `((?:0x)?[0-9a-fA-F]+)`
 
 var (
-	reBlockNum   = regexp.MustCompile(`^/block/(\d{1,12})$`)              //
-	reBlockTxNum = regexp.MustCompile(`^/block/(\d{1,12})/(\d{1,12})$`)   //
-	reTxID       = regexp.MustCompile(`^/tx/([a-f0-9]{1,16})$`)           //
-	reTxHash     = regexp.MustCompile(`^/tx/([a-f0-9]{64})$`)             //
-	reAddrInfo   = regexp.MustCompile(`^/address/` + reAddress + `$`)     //
-	reUserInfo   = regexp.MustCompile(`^/user/` + reAddress + `$`)        //
-	reTxsAddr    = regexp.MustCompile(`^/txs/` + reAddress + `$`)         //
-	reAddrTxs    = regexp.MustCompile(`^/address/` + reAddress + `/txs$`) //
+	reBlockNum      = regexp.MustCompile(`^/block/(\d{1,12})$`)                       //
+	reBlockHeader   = regexp.MustCompile(`^/block/(\d{1,12})/header$`)                //
+	reBlockTxNum    = regexp.MustCompile(`^/block/(\d{1,12})/(\d{1,12})$`)            //
+	reTxID          = regexp.MustCompile(`^/tx/([a-f0-9]{1,16})$`)                    //
+	reTxHash        = regexp.MustCompile(`^/tx/([a-f0-9]{64})$`)                      //
+	reTxClaim       = regexp.MustCompile(`^/tx/([a-f0-9]{1,16}|[a-f0-9]{64})/claim$`) //
+	reAddrInfo      = regexp.MustCompile(`^/address/` + reAddress + `$`)              //
+	reUserInfo      = regexp.MustCompile(`^/user/` + reAddress + `$`)                 //
+	reTxsAddr       = regexp.MustCompile(`^/txs/` + reAddress + `$`)                  //
+	reAddrTxs       = regexp.MustCompile(`^/address/` + reAddress + `/txs$`)          //
+	reAssetHolders  = regexp.MustCompile(`^/asset/` + reAsset + `/holders$`)          //
+	reAssetTxs      = regexp.MustCompile(`^/asset/` + reAsset + `/txs$`)              //
+	reSourceInfo    = regexp.MustCompile(`^/source/([^/]+)$`)                         //
+	reSourceTxs     = regexp.MustCompile(`^/source/([^/]+)/txs$`)                     //
+	reAddrSources   = regexp.MustCompile(`^/address/` + reAddress + `/sources$`)      //
+	reUserInvites   = regexp.MustCompile(`^/user/` + reAddress + `/invites$`)         //
+	reUserReferrals = regexp.MustCompile(`^/user/` + reAddress + `/referrals$`)       //
+	reAddrStake     = regexp.MustCompile(`^/address/` + reAddress + `/stake$`)        //
 )
 
 /**
@@ -64,32 +79,112 @@ API /v0/
 
 ./ 								-> html dashboard
 
+./stat/series					-> [{timestamp, statistic},...]	(statistic at the end of each interval)
+	&from=<unixtime:int|date:YYYY-MM-DD|time:RFC3339>	(by default: 30 intervals before to-param)
+	&to=<unixtime:int|date:YYYY-MM-DD|time:RFC3339>		(by default: now)
+	&interval=hour|day		(by default: day)
+
 ./blocks						-> [{block},...]
+	&cursor=<cursor>		(cursor mode: response is {items:[...], next_cursor})
 	&offset=<blockNum:int>
 	&limit=<limit:int>
 	&order=asc|desc
 
 ./block/<num:int|hash:hex>		-> {block}
 
+./block/<num:int>/header		-> {blockHeader}
+
+./blocks/stream					-> [{block},...]	(blocks after block #from, then new blocks as they are committed; stream is closed after 25 sec)
+	&from=<blockNum:int>
+
 ./tx/<txID|txHash:hex>			-> {tx}
 
+./tx/<txID|txHash:hex>/claim	-> {header, tx, proof}	(proof of cross-chain outs of tx for object.Claim on destination chain)
+
 ./txs   						-> [{tx},...]
 	&address=<address>
 	&asset=<asset:hex>
 	&memo=<memo:uint64>
+	&cursor=<cursor>		(cursor mode: response is {items:[...], next_cursor})
 	&offset=<ts:int>
 	&limit=<limit:int>
 	&order=asc|desc			(by default: asc)
 	&txType=-1|0|1|2 		(by default: -1)
+	&direction=in|out
+	&counterparty=<address>
+	&min_amount=<amount:int>
+	&max_amount=<amount:int>
+	&from=<unixtime:int|date:YYYY-MM-DD|time:RFC3339>
+	&to=<unixtime:int|date:YYYY-MM-DD|time:RFC3339>
+	&comment=<substring>
 
 ./txs/<address>					-> synonym of /txs/?address=<address>
 
 ./user/<address>				-> {userTx}
 ./user/@<username>				-> {userTx}
 
+./users						-> [{userTx},...]	(users ordered by registration)
+	&offset=<txUID:int>
+	&limit=<limit:int>
+	&order=asc|desc			(by default: asc)
+
+./users/search					-> [{userTx},...]	(users by prefix of nick)
+	&q=<nickPrefix>
+	&limit=<limit:int>
+
+./user/<address>/invites		-> [{userTx},...]	(users invited by user)
+	&offset=<txUID:int>
+	&limit=<limit:int>
+	&order=asc|desc			(by default: asc)
+
+./user/<address>/referrals		-> {referral tree with totals of referral rewards}
+	&asset
+	&depth=<levels:int>		(by default: 1)
+	&limit=<limit:int>		(max count of users in tree)
+
 ./address/<address>				-> {addressInfo, balance}
 	&memo
 	&asset
+	&at=<blockNum:int|unixtime:int|date:YYYY-MM-DD|time:RFC3339>	(balance at block height or at time)
+
+./richlist						-> [{holder},...]	(addresses ordered by balance desc)
+	&asset=<asset:hex>
+	&limit=<limit:int>
+
+./faucet						-> {tx}	(emission of coins to address; devnet mode only)
+	&address=<address>
+	&amount=<amount:int>
+	&asset=<asset:hex>
+
+./emission/schedule				-> [{from, rate, max_per_block},...]	(published schedule of primary emission)
+
+./validators					-> {consensus, validators, next_leader}	(validator set of proof-of-authority consensus)
+
+./asset/<asset:hex>/txs			-> [{tx},...]	(all state changes of asset)
+	&offset=<txUID:int>
+	&limit=<limit:int>
+	&order=asc|desc			(by default: desc)
+	&txType=-1|0|1|2 		(by default: -1)
+
+./asset/<asset:hex>/holders		-> {count of holders, top holders, distribution of holders by balance}
+	&limit=<limit:int>			(count of top holders)
+
+./source/<sourceID>				-> {current likes, current address, total supply by addresses}
+	&asset
+
+./source/<sourceID>/txs			-> [{tx},...]	(emission txs by media-source)
+	&asset
+	&offset=<txUID:int>
+	&limit=<limit:int>
+	&order=asc|desc			(by default: desc)
+
+./address/<address>/stake		-> {stake, stake_since, reward}	(accrued and unminted reward of proof-of-stake minting)
+	&asset
+
+./address/<address>/sources		-> [{source, total},...]	(media-sources which paid to address)
+	&asset
+	&offset=<sourceID>
+	&limit=<limit:int>
 
 <address> := "LikeXXXXXXXXXXXXXX" | <pubKey:base58> | @<nick> | 0x<userID:hex>
 
@@ -137,12 +232,30 @@ func (ctx *Context) Exec() {
 	case path == "/info":
 		ctx.WriteObject(ctx.bc.Info())
 
+	case path == "/stat/series":
+		interval := ctx.getInterval()
+		to := ctx.getTime("to", time.Now())
+		from := ctx.getTime("from", to.Add(-30*interval))
+		series, err := ctx.bc.StatSeries(from, to, interval)
+		if err != nil {
+			ctx.Panic400(err)
+		}
+		ctx.WriteObject(series)
+
 	case path == "/mempool/txs":
+		var txs []*blockchain.Transaction
+		var err error
 		if ctx.Get("address", "") != "" {
 			addr, _, _ := ctx.getAddress()
-			ctx.WriteObject(ctx.bc.Mempool.TxsByAddress(addr))
+			txs, err = ctx.bc.Mempool.TxsByAddress(addr)
 		} else {
-			ctx.WriteObject(ctx.bc.Mempool.AllTxs())
+			txs, err = ctx.bc.Mempool.AllTxs()
+		}
+		if ctx.cursorMode() && err == nil {
+			page, next := pageTxs(txs, ctx.getCursor(), ctx.getLimit())
+			ctx.WriteObject(&listPage{page, ctx.nextCursor(next)})
+		} else {
+			ctx.WriteObject(txs, err)
 		}
 
 	case path == "/new-txs":
@@ -164,6 +277,18 @@ func (ctx *Context) Exec() {
 		err := ctx.bc.Mempool.PutTx(tx)
 		ctx.WriteObject(tx, err)
 
+	case path == "/faucet":
+		if ctx.faucet == nil {
+			ctx.Panic404(errFaucetIsOff)
+		}
+		addr, _, asset := ctx.getAddress() // address
+		amount := ctx.getAmount()          // amount in nano-coins
+		tx, err := ctx.faucet(addr, asset, amount)
+		if err != nil {
+			ctx.Panic400(err)
+		}
+		ctx.WriteObject(tx)
+
 	case path == "/new-key":
 		prv := ctx.getPrivateKey() // prv OR seed
 		memo := ctx.getMemo()
@@ -209,20 +334,113 @@ func (ctx *Context) Exec() {
 		// /blocks
 	case path == "/blocks":
 		ofst, limit, ord := ctx.getOffset(), ctx.getLimit(), ctx.getOrder("asc")
+		if cur := ctx.getCursor(); cur != nil {
+			ofst = cur.TxUID
+		}
 		strm := ctx.OpenStream()
 		defer strm.Close()
+		var lastNum uint64
+		var n int64
 		ctx.bc.FetchBlocks(ofst, limit, ord, func(block *blockchain.Block) error {
+			lastNum, n = block.Num, n+1
 			return strm.WriteObject(block)
 		})
+		if n == limit && !(ord && lastNum == 1) {
+			strm.SetNextCursor(ctx.nextCursor(&db.Cursor{TxUID: lastNum}))
+		}
+
+		// /blocks/stream?from
+	case path == "/blocks/stream":
+		ctx.streamBlocks(ctx.getUint("from", 0, 10))
+
+		// /richlist?asset&limit
+	case path == "/richlist":
+		asset, limit := ctx.getAsset(), ctx.getLimit()
+		strm := ctx.OpenStream()
+		defer strm.Close()
+		ctx.bc.FetchRichList(asset, limit, func(h *db.Holder) error {
+			return strm.WriteObject(h)
+		})
+
+		// /emission/schedule
+	case path == "/emission/schedule":
+		ctx.WriteObject(ctx.bc.Cfg.EmissionSchedule)
+
+		// /validators
+	case path == "/validators":
+		ctx.WriteObject(ctx.bc.ValidatorSet())
+
+		// /asset/<asset>/txs?offset&limit&order&txtype
+	case pathMatch(reAssetTxs):
+		asset := ctx.parseAsset(q[1])
+		_, _, _, offset, limit, order, txType := ctx.parseQueryParams("")
+		if err := ctx.bc.CheckAssetFeed(asset, offset, order); err == db.ErrHistoryPruned {
+			ctx.Panic(http.StatusGone, err)
+		}
+		strm := ctx.OpenStream()
+		defer strm.Close()
+		ctx.bc.FetchTransactionsByAsset(asset, offset, limit, order, txType, func(tx *blockchain.Transaction) error {
+			return strm.WriteObject(tx)
+		})
+
+		// /source/<sourceID>?asset
+	case pathMatch(reSourceInfo):
+		if inf, err := ctx.bc.SourceInfo(ctx.getAsset(), q[1]); err == db.ErrSourceNotFound {
+			ctx.Panic404(err)
+		} else {
+			ctx.WriteObject(inf, err)
+		}
+
+		// /source/<sourceID>/txs?asset&offset&limit&order
+	case pathMatch(reSourceTxs):
+		asset, offset, limit, order := ctx.getAsset(), ctx.getOffset(), ctx.getLimit(), ctx.getOrder("desc")
+		ctx.checkFullIndexes()
+		strm := ctx.OpenStream()
+		defer strm.Close()
+		ctx.bc.FetchSourceTxs(asset, q[1], offset, limit, order, func(tx *blockchain.Transaction) error {
+			return strm.WriteObject(tx)
+		})
+
+		// /address/<address>/sources?asset&offset&limit
+	case pathMatch(reAddrSources):
+		addr, _, asset := ctx.parseAddress(q[1])
+		offset, limit := ctx.Get("offset", ""), ctx.getLimit()
+		ctx.checkFullIndexes()
+		strm := ctx.OpenStream()
+		defer strm.Close()
+		ctx.bc.FetchAddressSources(asset, addr, offset, limit, func(v *db.SourceSupply) error {
+			return strm.WriteObject(v)
+		})
+
+		// /address/<address>/stake?asset
+	case pathMatch(reAddrStake):
+		addr, _, asset := ctx.parseAddress(q[1])
+		ctx.WriteObject(ctx.bc.StakeInfo(addr, asset))
+
+		// /asset/<asset>/holders?limit
+	case pathMatch(reAssetHolders):
+		asset := ctx.parseAsset(q[1])
+		ctx.WriteObject(ctx.bc.AssetHolders(asset, ctx.getLimit()))
 
 	// 	/txs/<address>  OR   /address/<address>/txs
 	case pathMatch(reTxsAddr) || pathMatch(reAddrTxs):
 		addr, memo, asset, offset, limit, order, txType := ctx.parseQueryParams(q[1])
+		filter := ctx.getTxFilter(txType)
+		cur := ctx.getCursor()
+		if cur == nil {
+			cur = &db.Cursor{TxUID: offset}
+		}
+		if !filter.Counterparty.Empty() {
+			ctx.checkFullIndexes()
+		} else if err := ctx.bc.CheckHistory(asset, addr, memo, cur.TxUID, order); err == db.ErrHistoryPruned {
+			ctx.Panic(http.StatusGone, err)
+		}
 		strm := ctx.OpenStream()
 		defer strm.Close()
-		ctx.bc.FetchTransactionsByAddr(asset, addr, memo, offset, limit, order, txType, func(tx *blockchain.Transaction, _ bignum.Int) error {
+		next, _ := ctx.bc.FetchFilteredTransactionsByAddr(asset, addr, memo, cur, limit, order, filter, func(tx *blockchain.Transaction, _ bignum.Int) error {
 			return strm.WriteObject(tx)
 		})
+		strm.SetNextCursor(ctx.nextCursor(next))
 
 		// 	/block/<num>
 	case pathMatch(reBlockNum):
@@ -233,6 +451,15 @@ func (ctx *Context) Exec() {
 			ctx.WriteObject(block, err)
 		}
 
+		// 	/block/<num>/header
+	case pathMatch(reBlockHeader):
+		num, _ := strconv.ParseUint(q[1], 0, 64)
+		if h, err := ctx.bc.BlockHeader(num); err == db.ErrBlockNotFound {
+			ctx.Panic404(err)
+		} else {
+			ctx.WriteObject(h, err)
+		}
+
 		// 	/block/<num>/<num>
 	case pathMatch(reBlockTxNum):
 		blockNum, _ := strconv.ParseUint(q[1], 0, 64)
@@ -245,6 +472,47 @@ func (ctx *Context) Exec() {
 		tx, _, err := ctx.bc.UserByID(userID)
 		ctx.WriteObject(tx, err)
 
+		// 	/users?offset&limit&order
+	case path == "/users":
+		offset, limit, order := ctx.getOffset(), ctx.getLimit(), ctx.getOrder("asc")
+		strm := ctx.OpenStream()
+		defer strm.Close()
+		ctx.bc.FetchUsers(offset, limit, order, func(tx *blockchain.Transaction, _ *object.User) error {
+			return strm.WriteObject(tx)
+		})
+
+		// 	/users/search?q&limit
+	case path == "/users/search":
+		prefix, limit := ctx.Get("q", ""), ctx.getLimit()
+		strm := ctx.OpenStream()
+		defer strm.Close()
+		ctx.bc.SearchUsers(prefix, limit, func(tx *blockchain.Transaction, _ *object.User) error {
+			return strm.WriteObject(tx)
+		})
+
+		// 	/user/<address>/invites?offset&limit&order
+	case pathMatch(reUserInvites):
+		userID := ctx.parseUserID(q[1])
+		offset, limit, order := ctx.getOffset(), ctx.getLimit(), ctx.getOrder("asc")
+		strm := ctx.OpenStream()
+		defer strm.Close()
+		ctx.bc.FetchInvitedUsers(userID, offset, limit, order, func(tx *blockchain.Transaction, _ *object.User) error {
+			return strm.WriteObject(tx)
+		})
+
+		// 	/user/<address>/referrals?asset&depth&limit
+	case pathMatch(reUserReferrals):
+		userID := ctx.parseUserID(q[1])
+		depth := ctx.getUint("depth", 1, 10)
+		if depth > maxReferralsDepth {
+			ctx.Panic400Str("depth-param is too much")
+		}
+		ctx.WriteObject(ctx.bc.ReferralTree(ctx.getAsset(), userID, int(depth), int(ctx.getLimit())))
+
+		//	/tx/<txID|hash:hex>/claim
+	case pathMatch(reTxClaim):
+		ctx.writeTxClaim(q[1])
+
 		//	/tx/<hash:hex>
 	case pathMatch(reTxHash):
 		txHash, _ := hex.DecodeString(q[1])
@@ -255,20 +523,67 @@ func (ctx *Context) Exec() {
 		id, _ := strconv.ParseUint(q[1], 16, 64)
 		ctx.WriteObject(ctx.bc.TransactionByID(id))
 
-		// 	/address?address&memo&asset
+		// 	/address?address&memo&asset&at
 	case path == "/address":
-		ctx.WriteObject(ctx.bc.AddressInfo(ctx.getAddress()))
+		ctx.writeAddressInfo(ctx.getAddress())
 
-		// 	/address/<address>?memo&asset
+		// 	/address/<address>?memo&asset&at
 	case pathMatch(reAddrInfo):
-		ctx.WriteObject(ctx.bc.AddressInfo(ctx.parseAddress(q[1])))
+		ctx.writeAddressInfo(ctx.parseAddress(q[1]))
 
 	default:
 		ctx.Panic404(err404)
 	}
 }
 
-var err404 = errors.New("not found")
+var (
+	err404         = errors.New("not found")
+	errFaucetIsOff = errors.New("faucet is off (devnet mode only)")
+)
+
+// listPage is the page of list returned in cursor mode
+type listPage struct {
+	Items      interface{} `json:"items"`       //
+	NextCursor string      `json:"next_cursor"` // empty - no more items
+}
+
+// pageTxs returns page of txs ordered by txID after cursor
+func pageTxs(txs []*blockchain.Transaction, cur *db.Cursor, limit int64) (page []*blockchain.Transaction, next *db.Cursor) {
+	sort.Slice(txs, func(i, j int) bool { return txs[i].ID() < txs[j].ID() })
+	page = []*blockchain.Transaction{}
+	for _, tx := range txs {
+		if cur != nil && tx.ID() <= cur.TxUID {
+			continue
+		}
+		if int64(len(page)) == limit {
+			next = &db.Cursor{TxUID: page[len(page)-1].ID()}
+			break
+		}
+		page = append(page, tx)
+	}
+	return
+}
+
+const maxReferralsDepth = 10
+
+func (c *Context) writeAddressInfo(addr crypto.Address, memo uint64, asset assets.Asset) {
+	if c.Get("at", "") == "" {
+		c.WriteObject(c.bc.AddressInfo(addr, memo, asset))
+		return
+	}
+	inf, err := c.bc.AddressInfoAt(addr, memo, asset, c.getBlockNumAt())
+	if err == db.ErrHistoryPruned {
+		c.Panic(http.StatusGone, err)
+	}
+	c.WriteObject(inf, err)
+}
+
+// checkFullIndexes responds 410 if indexes of the query are not restored from snapshot (see BlockchainStorage.CheckFullIndexes)
+func (c *Context) checkFullIndexes() {
+	if err := c.bc.CheckFullIndexes(); err == db.ErrIncompleteIndex {
+		c.Panic(http.StatusGone, err)
+	}
+}
 
 type HTTPError struct {
 	Code int
@@ -283,6 +598,31 @@ func (c *Context) parseRequestBody(v interface{}) {
 	}
 }
 
+func (c *Context) writeTxClaim(strID string) {
+	var tx *blockchain.Transaction
+	var err error
+	if len(strID) == 64 {
+		txHash, _ := hex.DecodeString(strID)
+		tx, err = c.bc.TransactionByHash(txHash)
+	} else {
+		id, _ := strconv.ParseUint(strID, 16, 64)
+		tx, err = c.bc.TransactionByID(id)
+	}
+	if err == db.ErrIncompleteIndex {
+		c.Panic(http.StatusGone, err)
+	} else if err != nil {
+		c.Panic500(err)
+	}
+	if tx == nil {
+		c.Panic404(err404)
+	}
+	claim, err := c.bc.TxClaim(tx)
+	if err == db.ErrTxHasNoCrossChainOuts {
+		c.Panic400(err)
+	}
+	c.WriteObject(claim, err)
+}
+
 func (c *Context) Panic(code int, err error) {
 	panic(&HTTPError{code, err.Error()})
 }
@@ -306,6 +646,9 @@ func (c *Context) Panic400Str(err string) {
 
 func (c *Context) WriteHTML(data []byte, ee ...error) {
 	if len(ee) > 0 && ee[0] != nil {
+		if ee[0] == db.ErrIncompleteIndex {
+			c.Panic(http.StatusGone, ee[0])
+		}
 		c.Panic500(ee[0])
 		return
 	}
@@ -359,10 +702,50 @@ func (c *Context) WriteObject(obj interface{}, ee ...error) {
 	}
 }
 
+// blocksStreamDuration is the max duration of blocks stream (less than http write timeout); client reconnects after it
+const blocksStreamDuration = 25 * time.Second
+
+const blocksStreamBatch = 100
+
+// streamBlocks writes blocks after block #from and then new blocks as they are committed
+func (c *Context) streamBlocks(from uint64) {
+	strm := c.OpenStream()
+	defer strm.Close()
+	timeout := time.After(blocksStreamDuration)
+	for {
+		notify := c.bc.NewBlocksNotify()
+		n := 0
+		err := c.bc.FetchBlocks(from, blocksStreamBatch, false, func(block *blockchain.Block) error {
+			from, n = block.Num, n+1
+			return strm.WriteObject(block)
+		})
+		if err != nil {
+			return
+		}
+		strm.Flush()
+		if n == blocksStreamBatch { // catching up
+			select {
+			case <-timeout:
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case <-notify:
+		case <-timeout:
+			return
+		case <-c.req.Context().Done():
+			return
+		}
+	}
+}
+
 func (c *Context) OpenStream() (s *RWStream) {
 	s = &RWStream{
 		rw:     c.rw,
 		pretty: len(c.req.Form["pretty"]) > 0,
+		paging: c.cursorMode(),
 	}
 	var err error
 	switch c.Get("encoding", "") {
@@ -370,7 +753,11 @@ func (c *Context) OpenStream() (s *RWStream) {
 		s.encoding = encodingJSON
 		c.rw.Header().Set("Content-Type", "application/json; charset=utf-8")
 		c.rw.WriteHeader(http.StatusOK)
-		_, err = io.Copy(c.rw, bytes.NewBufferString("[\n"))
+		if s.paging {
+			_, err = io.Copy(c.rw, bytes.NewBufferString("{\"items\":[\n"))
+		} else {
+			_, err = io.Copy(c.rw, bytes.NewBufferString("[\n"))
+		}
 	case "binary":
 		s.encoding = encodingBinary
 		c.rw.Header().Set("Content-Type", "binary")
@@ -385,10 +772,17 @@ func (c *Context) OpenStream() (s *RWStream) {
 }
 
 type RWStream struct {
-	rw       http.ResponseWriter
-	encoding int
-	pretty   bool
-	cnt      int64
+	rw         http.ResponseWriter
+	encoding   int
+	pretty     bool
+	cnt        int64
+	paging     bool   // response is the page {items, next_cursor}
+	nextCursor string //
+}
+
+// SetNextCursor sets cursor of the next page; the cursor is written by Close
+func (s *RWStream) SetNextCursor(cursor string) {
+	s.nextCursor = cursor
 }
 
 const (
@@ -420,13 +814,26 @@ func (s *RWStream) WriteObject(v interface{}) (err error) {
 	return
 }
 
+// Flush sends buffered data to client
+func (s *RWStream) Flush() {
+	if f, ok := s.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func (s *RWStream) Close() (err error) {
 	var buf = bytes.NewBuffer(nil)
 	switch s.encoding {
 	case encodingJSON:
 		buf.WriteString("\n]")
+		if s.paging {
+			fmt.Fprintf(buf, ",\"next_cursor\":%s}", enc.JSON(s.nextCursor))
+		}
 	case encodingBinary:
 		buf.WriteByte(0)
+		if s.paging {
+			buf.Write(bin.Encode(s.nextCursor))
+		}
 	}
 	_, err = io.Copy(s.rw, buf)
 	return
@@ -508,6 +915,14 @@ func (c *Context) getAsset() assets.Asset {
 	return asset
 }
 
+func (c *Context) parseAsset(s string) assets.Asset {
+	asset, err := assets.ParseAsset(s)
+	if err != nil || asset.Empty() {
+		c.Panic400Str("incorrect asset")
+	}
+	return asset
+}
+
 func (c *Context) getLimit() int64 {
 	n, err := strconv.ParseInt(c.Get("limit", "100"), 0, 64)
 	if n <= 0 || err != nil {
@@ -535,6 +950,51 @@ func (c *Context) getTxType() int {
 	return n
 }
 
+// minUnixTime is the min value of at-param which is parsed as unix-time (lesser values are block heights)
+const minUnixTime = 1e9
+
+// getBlockNumAt returns block num by at-param: block height, unix-time, date or RFC3339-time
+func (c *Context) getBlockNumAt() uint64 {
+	if n, err := strconv.ParseUint(c.Get("at", ""), 10, 64); err == nil && n < minUnixTime {
+		return n
+	}
+	num, err := c.bc.BlockNumAt(c.getTime("at", time.Time{}))
+	if err != nil {
+		c.Panic500(err)
+	}
+	return num
+}
+
+// getTime parses time-param: unix-time, date or RFC3339-time
+func (c *Context) getTime(name string, defaultVal time.Time) time.Time {
+	s := c.Get(name, "")
+	if s == "" {
+		return defaultVal
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0)
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t
+	}
+	c.Panic400Str("incorrect " + name + "-param")
+	return defaultVal
+}
+
+func (c *Context) getInterval() time.Duration {
+	switch c.Get("interval", "day") {
+	case "hour":
+		return time.Hour
+	case "day":
+		return 24 * time.Hour
+	}
+	c.Panic400Str("incorrect interval-param")
+	return 0
+}
+
 func (c *Context) getOrder(defaultValue string) (desc bool) {
 	switch strings.ToLower(c.Get("order", defaultValue)) {
 	case "asc", "":
@@ -557,6 +1017,78 @@ func (c *Context) parseQueryParams(sAddr string) (addr crypto.Address, memo uint
 	return
 }
 
+// cursorMode returns true if cursor-param is set (even empty); list is returned as the page {items, next_cursor}
+func (c *Context) cursorMode() bool {
+	_, ok := c.req.Form["cursor"]
+	return ok
+}
+
+// getCursor parses cursor-param; cursor must be created by the same query
+func (c *Context) getCursor() *db.Cursor {
+	cur, err := db.ParseCursor(c.Get("cursor", ""))
+	if err != nil {
+		c.Panic400(err)
+	}
+	if cur != nil && cur.Filter != c.queryHash() {
+		c.Panic400Str("cursor does not match query params")
+	}
+	return cur
+}
+
+// nextCursor returns string of the next page cursor bound to query params
+func (c *Context) nextCursor(cur *db.Cursor) string {
+	if cur == nil {
+		return ""
+	}
+	cur.Filter = c.queryHash()
+	return cur.String()
+}
+
+// queryHash returns hash of path and query params excluding paging and format params
+func (c *Context) queryHash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(c.req.URL.Path))
+	keys := make([]string, 0, len(c.req.Form))
+	for key := range c.req.Form {
+		switch key {
+		case "cursor", "offset", "limit", "pretty", "encoding":
+		default:
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "\n%s=%s", key, c.Get(key, ""))
+	}
+	return h.Sum64()
+}
+
+func (c *Context) getTxFilter(txType int) *db.TxFilter {
+	f := db.NewTxFilter(txType)
+	switch c.Get("direction", "") {
+	case "":
+	case "in":
+		f.Direction = db.TxDirIn
+	case "out":
+		f.Direction = db.TxDirOut
+	default:
+		c.Panic400Str("incorrect direction-param")
+	}
+	if s := c.Get("counterparty", ""); s != "" {
+		addr, _, err := c.bc.AddressByStr(s)
+		if err != nil || addr.Empty() {
+			c.Panic400Str("incorrect counterparty-param")
+		}
+		f.Counterparty = addr
+	}
+	f.MinAmount = bignum.NewInt(int64(c.getUint("min_amount", 0, 10)))
+	f.MaxAmount = bignum.NewInt(int64(c.getUint("max_amount", 0, 10)))
+	f.FromTime = c.getTime("from", time.Time{})
+	f.ToTime = c.getTime("to", time.Time{})
+	f.Comment = c.Get("comment", "")
+	return f
+}
+
 func (c *Context) parseAddress(s string) (addr crypto.Address, memo uint64, asset assets.Asset) {
 	if s == "" {
 		s = c.Get("address", "")
@@ -41,5 +41,7 @@ func (s *WebServer) Start() error {
 }
 
 func (s *WebServer) ServeHTTP(rw http.ResponseWriter, rq *http.Request) {
-	NewContext(rq, rw, s.bc, "").Exec()
+	ctx := NewContext(rq, rw, s.bc, "")
+	ctx.faucet = s.cfg.Faucet
+	ctx.Exec()
 }